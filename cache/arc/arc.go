@@ -0,0 +1,181 @@
+// Package arc implements Adaptive Replacement Cache, it balance recency
+// and frequency by remembering recently evicted keys, so a scan only
+// flush the recency part of cache.
+// https://www.usenix.org/legacy/events/fast03/tech/full_papers/megiddo/megiddo.pdf
+package arc
+
+import (
+	"container/list"
+	"runtime"
+
+	"github.com/haormj/util/cache/internal/bounded"
+	"github.com/haormj/util/cache/internal/janitor"
+)
+
+// ArcCache implements cache.BaseCache by using memory
+type ArcCache struct {
+	*Cache[string, interface{}]
+}
+
+func NewArcCache(opts ...Option) *ArcCache {
+	arcCache := &ArcCache{
+		Cache: New[string, interface{}](opts...),
+	}
+	return arcCache
+}
+
+// Cache is a type-safe arc cache, implements cache.TypedCache
+type Cache[K comparable, V any] struct {
+	*bounded.Cache[K, V]
+	// janitor only hold bounded.Cache, so Cache can be garbage
+	// collected and the finalizer stop the janitor
+	janitor *janitor.Janitor
+}
+
+func New[K comparable, V any](opts ...Option) *Cache[K, V] {
+	option := newOptions(opts...)
+
+	c := &Cache[K, V]{
+		Cache: bounded.New[K, V](newPolicy[K](option.MaxEntries)),
+	}
+	if option.CleanupInterval > 0 {
+		c.janitor = janitor.Run(option.CleanupInterval, c.Cache.DeleteExpired)
+		runtime.SetFinalizer(c, stopJanitor[K, V])
+	}
+	return c
+}
+
+func stopJanitor[K comparable, V any](c *Cache[K, V]) {
+	c.janitor.Stop()
+}
+
+// policy keep cached keys in t1 (seen once) and t2 (seen at least
+// twice), and evicted keys in ghost lists b1 and b2. p is the target
+// size of t1, adapted by hits in ghost lists. front of list is MRU
+type policy[K comparable] struct {
+	c       int
+	p       int
+	t1      *list.List
+	t2      *list.List
+	b1      *list.List
+	b2      *list.List
+	entries map[K]*entry
+}
+
+type entry struct {
+	list *list.List
+	elem *list.Element
+}
+
+func newPolicy[K comparable](maxEntries int) *policy[K] {
+	return &policy[K]{
+		c:       maxEntries,
+		t1:      list.New(),
+		t2:      list.New(),
+		b1:      list.New(),
+		b2:      list.New(),
+		entries: make(map[K]*entry),
+	}
+}
+
+func (p *policy[K]) Add(key K) []K {
+	var evicted []K
+	if e, ok := p.entries[key]; ok && e.list == p.b1 {
+		delta := 1
+		if p.b2.Len() > p.b1.Len() {
+			delta = p.b2.Len() / p.b1.Len()
+		}
+		p.p += delta
+		if p.p > p.c {
+			p.p = p.c
+		}
+		evicted = p.replace(false)
+		p.move(key, p.t2)
+		return evicted
+	}
+	if e, ok := p.entries[key]; ok && e.list == p.b2 {
+		delta := 1
+		if p.b1.Len() > p.b2.Len() {
+			delta = p.b1.Len() / p.b2.Len()
+		}
+		p.p -= delta
+		if p.p < 0 {
+			p.p = 0
+		}
+		evicted = p.replace(true)
+		p.move(key, p.t2)
+		return evicted
+	}
+
+	if p.t1.Len()+p.b1.Len() >= p.c {
+		if p.t1.Len() < p.c {
+			p.removeBack(p.b1)
+			evicted = p.replace(false)
+		} else {
+			evicted = append(evicted, p.removeBack(p.t1))
+		}
+	} else if total := p.t1.Len() + p.t2.Len() + p.b1.Len() + p.b2.Len(); total >= p.c {
+		if total >= 2*p.c {
+			p.removeBack(p.b2)
+		}
+		evicted = p.replace(false)
+	}
+	p.entries[key] = &entry{list: p.t1, elem: p.t1.PushFront(key)}
+	return evicted
+}
+
+func (p *policy[K]) Access(key K) {
+	if e, ok := p.entries[key]; ok && (e.list == p.t1 || e.list == p.t2) {
+		p.move(key, p.t2)
+	}
+}
+
+func (p *policy[K]) Miss(key K) {}
+
+func (p *policy[K]) Remove(key K) {
+	if e, ok := p.entries[key]; ok {
+		e.list.Remove(e.elem)
+		delete(p.entries, key)
+	}
+}
+
+func (p *policy[K]) Clear() {
+	p.p = 0
+	p.t1.Init()
+	p.t2.Init()
+	p.b1.Init()
+	p.b2.Init()
+	p.entries = make(map[K]*entry)
+}
+
+// replace evict lru key of t1 or t2 into its ghost list when cache is full
+func (p *policy[K]) replace(inB2 bool) []K {
+	if p.t1.Len()+p.t2.Len() < p.c {
+		return nil
+	}
+	from, to := p.t2, p.b2
+	if p.t1.Len() > 0 && (p.t1.Len() > p.p || (inB2 && p.t1.Len() == p.p)) {
+		from, to = p.t1, p.b1
+	}
+	if from.Len() == 0 {
+		return nil
+	}
+	victim := from.Back().Value.(K)
+	p.move(victim, to)
+	return []K{victim}
+}
+
+// move key to the front of l
+func (p *policy[K]) move(key K, l *list.List) {
+	e := p.entries[key]
+	e.list.Remove(e.elem)
+	e.list = l
+	e.elem = l.PushFront(key)
+}
+
+// removeBack forget lru key of l
+func (p *policy[K]) removeBack(l *list.List) K {
+	key := l.Back().Value.(K)
+	p.Remove(key)
+	return key
+}
@@ -0,0 +1,41 @@
+package arc
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestArcCache(t *testing.T) {
+	c := NewArcCache(MaxEntries(4))
+	for _, key := range []string{"a", "b"} {
+		c.Set(key, key, 0)
+		c.Get(key)
+	}
+	// scan only flush t1, frequent a and b stay in t2
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i, 0)
+	}
+	for _, key := range []string{"a", "b"} {
+		if v, ok := c.Get(key); !ok || v != key {
+			t.Errorf("expected:%s,got:%v,%v", key, v, ok)
+		}
+	}
+	if items := c.Stats().Items; items != 4 {
+		t.Errorf("expected:4,got:%d", items)
+	}
+	c.Clear()
+	if items := c.Stats().Items; items != 0 {
+		t.Errorf("expected:0,got:%d", items)
+	}
+}
+
+func TestArcCacheCleanup(t *testing.T) {
+	c := New[string, int](CleanupInterval(time.Millisecond * 20))
+	c.Set("a", 1, time.Millisecond*10)
+	c.Set("b", 2, time.Minute)
+	time.Sleep(time.Millisecond * 100)
+	if n := c.Len(); n != 1 {
+		t.Errorf("expected:1,got:%d", n)
+	}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotSupported is returned when BaseCache does not support the operation
+var ErrNotSupported = errors.New("cache: operation not supported by base cache")
+
+// atomicCache is implemented by BaseCache which support atomic
+// operations, such as lru.LruCache and ttl.TtlCache
+type atomicCache interface {
+	Add(string, interface{}, time.Duration) bool
+	Replace(string, interface{}, time.Duration) bool
+	CompareAndSwap(string, interface{}, interface{}, time.Duration) bool
+	Incr(string, int64, time.Duration) (int64, error)
+	Decr(string, int64, time.Duration) (int64, error)
+}
+
+// Add set value only if key is missing, report whether it is set
+func (dc defaultCache) Add(key string, value interface{}, ttl time.Duration) (bool, error) {
+	ac, ok := dc.BaseCache.(atomicCache)
+	if !ok {
+		return false, ErrNotSupported
+	}
+	ok = ac.Add(key, value, ttl)
+	if ok {
+		dc.state.forget(key)
+	}
+	return ok, nil
+}
+
+// Replace set value only if key exists, report whether it is set
+func (dc defaultCache) Replace(key string, value interface{}, ttl time.Duration) (bool, error) {
+	ac, ok := dc.BaseCache.(atomicCache)
+	if !ok {
+		return false, ErrNotSupported
+	}
+	ok = ac.Replace(key, value, ttl)
+	if ok {
+		dc.state.forget(key)
+	}
+	return ok, nil
+}
+
+// CompareAndSwap set new only if current value of key equals old,
+// report whether it is set. uncomparable values are never equal
+func (dc defaultCache) CompareAndSwap(key string, old, new interface{}, ttl time.Duration) (bool, error) {
+	ac, ok := dc.BaseCache.(atomicCache)
+	if !ok {
+		return false, ErrNotSupported
+	}
+	ok = ac.CompareAndSwap(key, old, new, ttl)
+	if ok {
+		dc.state.forget(key)
+	}
+	return ok, nil
+}
+
+// Incr add delta to numeric value of key and return the result.
+// missing key is set to delta with ttl, otherwise its expiration is kept
+func (dc defaultCache) Incr(key string, delta int64, ttl time.Duration) (int64, error) {
+	ac, ok := dc.BaseCache.(atomicCache)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	dc.state.forget(key)
+	return ac.Incr(key, delta, ttl)
+}
+
+// Decr subtract delta from numeric value of key, same as Incr
+func (dc defaultCache) Decr(key string, delta int64, ttl time.Duration) (int64, error) {
+	ac, ok := dc.BaseCache.(atomicCache)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	dc.state.forget(key)
+	return ac.Decr(key, delta, ttl)
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"strings"
+	"time"
+)
+
+// GetMulti return values of keys which are found
+func (dc defaultCache) GetMulti(keys []string) map[string]interface{} {
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if v, ok := dc.Get(key); ok {
+			values[key] = v
+		}
+	}
+	return values
+}
+
+// SetMulti set all values with the same ttl
+func (dc defaultCache) SetMulti(values map[string]interface{}, ttl time.Duration) {
+	for key, value := range values {
+		dc.Set(key, value, ttl)
+	}
+}
+
+// DeleteMulti delete all keys
+func (dc defaultCache) DeleteMulti(keys []string) {
+	for _, key := range keys {
+		dc.Delete(key)
+	}
+}
+
+// prefixCache is implemented by BaseCache which find and delete keys by
+// prefix without listing all keys, such as resp and namespace views
+type prefixCache interface {
+	KeysPrefix(string) []string
+	DeletePrefix(string) int
+}
+
+// DeletePrefix delete all keys start with prefix, and return the number
+// of deleted keys. keys set during DeletePrefix may be kept
+func (dc defaultCache) DeletePrefix(prefix string) int {
+	defer dc.state.forgetPrefix(prefix)
+	if pc, ok := dc.BaseCache.(prefixCache); ok {
+		return pc.DeletePrefix(prefix)
+	}
+	return deletePrefix(dc.BaseCache, prefix)
+}
+
+// deletePrefix delete keys start with prefix one by one
+func deletePrefix(baseCache BaseCache, prefix string) int {
+	var n int
+	for _, key := range baseCache.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			baseCache.Delete(key)
+			n++
+		}
+	}
+	return n
+}
@@ -1,8 +1,10 @@
 package cache
 
 import (
+	"context"
 	"time"
 
+	"github.com/golang/groupcache/singleflight"
 	"github.com/spf13/cast"
 )
 
@@ -11,21 +13,67 @@ type Cache interface {
 	BaseCache
 	GetString(string) (string, bool)
 	GetStringE(string) (string, bool, error)
+	GetInt(string) (int, bool)
+	GetIntE(string) (int, bool, error)
+	GetInt64(string) (int64, bool)
+	GetInt64E(string) (int64, bool, error)
+	GetBool(string) (bool, bool)
+	GetBoolE(string) (bool, bool, error)
+	GetFloat64(string) (float64, bool)
+	GetFloat64E(string) (float64, bool, error)
+	GetDuration(string) (time.Duration, bool)
+	GetDurationE(string) (time.Duration, bool, error)
+	GetTime(string) (time.Time, bool)
+	GetTimeE(string) (time.Time, bool, error)
+	GetStringSlice(string) ([]string, bool)
+	GetStringSliceE(string) ([]string, bool, error)
+	GetStringMap(string) (map[string]interface{}, bool)
+	GetStringMapE(string) (map[string]interface{}, bool, error)
+	GetOrLoad(context.Context, string) (interface{}, error)
+	Stats() Stats
+	ResetStats()
+	Add(string, interface{}, time.Duration) (bool, error)
+	Replace(string, interface{}, time.Duration) (bool, error)
+	CompareAndSwap(string, interface{}, interface{}, time.Duration) (bool, error)
+	Incr(string, int64, time.Duration) (int64, error)
+	Decr(string, int64, time.Duration) (int64, error)
+	GetMulti([]string) map[string]interface{}
+	SetMulti(map[string]interface{}, time.Duration)
+	DeleteMulti([]string)
+	DeletePrefix(string) int
 }
 
-type BaseCache interface {
-	Set(string, interface{}, time.Duration)
-	Get(string) (interface{}, bool)
-	Delete(string)
+// TypedCache define type-safe cache interface
+type TypedCache[K comparable, V any] interface {
+	Set(K, V, time.Duration)
+	Get(K) (V, bool)
+	Delete(K)
 	Clear()
+	Keys() []K
+	Len() int
+}
+
+// BaseCache is the string/interface{} form of TypedCache
+type BaseCache interface {
+	TypedCache[string, interface{}]
 }
 
 func NewCache(opts ...Option) Cache {
 	option := newOptions(opts...)
 
+	if option.Namespace != "" {
+		option.BaseCache = newNamespacedCache(option.BaseCache, option.Namespace)
+	}
+
+	if option.OnEvict != nil {
+		setOnEvict(option.BaseCache, option.OnEvict)
+	}
+
 	defaultCache := defaultCache{
 		BaseCache: option.BaseCache,
 		option:    option,
+		group:     &singleflight.Group{},
+		state:     newLoaderState(option),
 	}
 
 	return defaultCache
@@ -34,6 +82,37 @@ func NewCache(opts ...Option) Cache {
 type defaultCache struct {
 	BaseCache
 	option Options
+	group  *singleflight.Group
+	// state is used by GetOrLoad, nil without NegativeTTL and SoftTTL
+	state *loaderState
+}
+
+// Set value of key, cached loader error and soft expiration of key
+// are dropped
+func (dc defaultCache) Set(key string, value interface{}, ttl time.Duration) {
+	dc.BaseCache.Set(key, value, ttl)
+	dc.state.forget(key)
+}
+
+// Delete key, cached loader error and soft expiration of key are dropped
+func (dc defaultCache) Delete(key string) {
+	dc.BaseCache.Delete(key)
+	dc.state.forget(key)
+}
+
+// Clear remove all keys, cached loader errors and soft expirations
+func (dc defaultCache) Clear() {
+	dc.BaseCache.Clear()
+	dc.state.forgetPrefix("")
+}
+
+// Get lookup value of key, cached loader error is treated as missing
+func (dc defaultCache) Get(key string) (interface{}, bool) {
+	v, ok, err := dc.lookup(key)
+	if err != nil {
+		return nil, false
+	}
+	return v, ok
 }
 
 func (dc defaultCache) GetString(key string) (string, bool) {
@@ -1,9 +1,16 @@
 package cache
 
 import (
+	"context"
+	"errors"
 	"log"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/haormj/util/cache/lru"
+	"github.com/haormj/util/cache/ttl"
 )
 
 func TestCache(t *testing.T) {
@@ -18,3 +25,421 @@ func TestCache(t *testing.T) {
 		time.Sleep(time.Second * 1)
 	}
 }
+
+func TestTypedCache(t *testing.T) {
+	var caches = map[string]TypedCache[string, int]{
+		"lru":   lru.New[string, int](),
+		"ttl":   ttl.New[string, int](),
+		"typed": NewTypedCache[int](NewCache()),
+	}
+	for name, c := range caches {
+		c.Set("a", 1, time.Minute)
+		if v, ok := c.Get("a"); !ok || v != 1 {
+			t.Errorf("%s: expected:1,got:%v,%v", name, v, ok)
+		}
+		c.Delete("a")
+		if _, ok := c.Get("a"); ok {
+			t.Errorf("%s: expected deleted", name)
+		}
+		c.Set("b", 2, time.Minute)
+		c.Clear()
+		if _, ok := c.Get("b"); ok {
+			t.Errorf("%s: expected cleared", name)
+		}
+	}
+
+	var _ BaseCache = lru.NewLruCache()
+	var _ BaseCache = ttl.NewTtlCache()
+}
+
+func TestGetOrLoad(t *testing.T) {
+	var loads int32
+	errNotFound := errors.New("not found")
+	cache := NewCache(
+		Loader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+			atomic.AddInt32(&loads, 1)
+			time.Sleep(time.Millisecond * 50)
+			if key == "missing" {
+				return nil, 0, errNotFound
+			}
+			return "value of " + key, time.Minute, nil
+		}),
+		NegativeTTL(time.Minute),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.GetOrLoad(context.Background(), "hello")
+			if err != nil || v != "value of hello" {
+				t.Errorf("expected:value of hello,got:%v,%v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if loads != 1 {
+		t.Errorf("expected:1 load,got:%d", loads)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetOrLoad(context.Background(), "missing"); err != errNotFound {
+			t.Errorf("expected:%v,got:%v", errNotFound, err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("expected:2 loads,got:%d", loads)
+	}
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("expected negative result hidden from Get")
+	}
+	cache.Set("missing", "set", time.Minute)
+	if v, err := cache.GetOrLoad(context.Background(), "missing"); err != nil || v != "set" {
+		t.Errorf("expected:set,got:%v,%v", v, err)
+	}
+
+	// values stored by loader are plain, atomic operations work on them
+	counter := NewCache(
+		Loader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+			return int64(1), time.Minute, nil
+		}),
+		SoftTTL(time.Minute),
+	)
+	if _, err := counter.GetOrLoad(context.Background(), "n"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := counter.Incr("n", 1, 0); err != nil || n != 2 {
+		t.Errorf("expected:2,got:%v,%v", n, err)
+	}
+	if ok, err := counter.CompareAndSwap("n", int64(2), int64(3), 0); err != nil || !ok {
+		t.Errorf("expected swapped,got:%v,%v", ok, err)
+	}
+}
+
+func TestOnEvict(t *testing.T) {
+	backends := map[string]BaseCache{
+		"lru": lru.NewLruCache(lru.MaxEntries(2), lru.CleanupInterval(time.Millisecond*20)),
+		"ttl": ttl.NewTtlCache(ttl.CleanupInterval(time.Millisecond * 20)),
+	}
+	for name, backend := range backends {
+		var mu sync.Mutex
+		reasons := make(map[string]EvictReason)
+		cache := NewCache(WithBaseCache(backend), OnEvict(func(key string, value interface{}, reason EvictReason) {
+			mu.Lock()
+			reasons[key] = reason
+			mu.Unlock()
+		}))
+		cache.Set("expired", 1, time.Millisecond*10)
+		time.Sleep(time.Millisecond * 100)
+		cache.Set("deleted", 2, 0)
+		cache.Delete("deleted")
+		cache.Set("cleared", 3, 0)
+		cache.Clear()
+
+		expected := map[string]EvictReason{
+			"expired": EvictExpired,
+			"deleted": EvictDeleted,
+			"cleared": EvictCleared,
+		}
+		mu.Lock()
+		for key, reason := range expected {
+			if reasons[key] != reason {
+				t.Errorf("%s: %s expected:%v,got:%v", name, key, reason, reasons[key])
+			}
+		}
+		mu.Unlock()
+	}
+
+	var evicted []string
+	c := lru.New[string, int](lru.MaxEntries(1))
+	c.OnEvict(func(key string, value int, reason EvictReason) {
+		if reason == EvictCapacity {
+			evicted = append(evicted, key)
+		}
+	})
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Errorf("expected:[a],got:%v", evicted)
+	}
+}
+
+func TestOnEvictShared(t *testing.T) {
+	backend := lru.NewLruCache()
+	var first, second int32
+	a := NewCache(WithBaseCache(backend), OnEvict(func(key string, value interface{}, reason EvictReason) {
+		atomic.AddInt32(&first, 1)
+	}))
+	NewCache(WithBaseCache(backend), OnEvict(func(key string, value interface{}, reason EvictReason) {
+		atomic.AddInt32(&second, 1)
+	}))
+	a.Set("a", 1, 0)
+	a.Delete("a")
+	if first != 1 || second != 1 {
+		t.Errorf("expected both called once,got:%d,%d", first, second)
+	}
+}
+
+func TestStats(t *testing.T) {
+	backends := map[string]BaseCache{
+		"lru": lru.NewLruCache(lru.MaxEntries(2)),
+		"ttl": ttl.NewTtlCache(),
+	}
+	for name, backend := range backends {
+		cache := NewCache(WithBaseCache(backend))
+		cache.Set("a", 1, 0)
+		cache.Set("b", 2, 0)
+		cache.Get("a")
+		cache.Get("c")
+		cache.Delete("b")
+		stats := cache.Stats()
+		expected := Stats{Hits: 1, Misses: 1, Sets: 2, Deletes: 1, Items: 1}
+		if stats != expected {
+			t.Errorf("%s: expected:%+v,got:%+v", name, expected, stats)
+		}
+		cache.ResetStats()
+		if stats := cache.Stats(); stats != (Stats{Items: 1}) {
+			t.Errorf("%s: expected reset,got:%+v", name, stats)
+		}
+	}
+
+	c := lru.New[string, int](lru.MaxEntries(1))
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	c.Get("c")
+	if stats := c.Stats(); stats.Evictions != 2 || stats.Expirations != 1 {
+		t.Errorf("expected:2 evictions 1 expiration,got:%+v", stats)
+	}
+}
+
+func TestTypedGetter(t *testing.T) {
+	cache := NewCache()
+	cache.Set("int", "42", 0)
+	cache.Set("bool", "true", 0)
+	cache.Set("duration", "1m", 0)
+	cache.Set("slice", []interface{}{"a", "b"}, 0)
+	if v, ok, err := cache.GetIntE("int"); !ok || err != nil || v != 42 {
+		t.Errorf("expected:42,got:%v,%v,%v", v, ok, err)
+	}
+	if v, ok := cache.GetBool("bool"); !ok || !v {
+		t.Errorf("expected:true,got:%v,%v", v, ok)
+	}
+	if v, ok := cache.GetDuration("duration"); !ok || v != time.Minute {
+		t.Errorf("expected:1m,got:%v,%v", v, ok)
+	}
+	if v, ok := cache.GetStringSlice("slice"); !ok || len(v) != 2 {
+		t.Errorf("expected:[a b],got:%v,%v", v, ok)
+	}
+	if _, ok, err := cache.GetInt64E("bool"); !ok || err == nil {
+		t.Error("expected error")
+	}
+
+	type Config struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+	cache.Set("struct", Config{Name: "a", Port: 1}, 0)
+	cache.Set("json", `{"name":"b","port":2}`, 0)
+	cache.Set("map", map[string]interface{}{"name": "c", "port": 3}, 0)
+	for key, expected := range map[string]Config{
+		"struct": {Name: "a", Port: 1},
+		"json":   {Name: "b", Port: 2},
+		"map":    {Name: "c", Port: 3},
+	} {
+		var config Config
+		ok, err := GetAs(cache, key, &config)
+		if !ok || err != nil || config != expected {
+			t.Errorf("%s: expected:%+v,got:%+v,%v,%v", key, expected, config, ok, err)
+		}
+	}
+}
+
+func TestAtomic(t *testing.T) {
+	backends := map[string]BaseCache{
+		"lru": lru.NewLruCache(),
+		"ttl": ttl.NewTtlCache(),
+	}
+	for name, backend := range backends {
+		cache := NewCache(WithBaseCache(backend))
+		if ok, err := cache.Add("a", "1", 0); !ok || err != nil {
+			t.Errorf("%s: expected added,got:%v,%v", name, ok, err)
+		}
+		if ok, _ := cache.Add("a", "2", 0); ok {
+			t.Errorf("%s: expected not added", name)
+		}
+		if ok, _ := cache.Replace("b", "2", 0); ok {
+			t.Errorf("%s: expected not replaced", name)
+		}
+		if ok, _ := cache.CompareAndSwap("a", "2", "3", 0); ok {
+			t.Errorf("%s: expected not swapped", name)
+		}
+		if ok, _ := cache.CompareAndSwap("a", "1", "3", 0); !ok {
+			t.Errorf("%s: expected swapped", name)
+		}
+		if v, _ := cache.GetString("a"); v != "3" {
+			t.Errorf("%s: expected:3,got:%v", name, v)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cache.Incr("counter", 2, time.Minute)
+			}()
+		}
+		wg.Wait()
+		if n, err := cache.Decr("counter", 1, 0); n != 199 || err != nil {
+			t.Errorf("%s: expected:199,got:%v,%v", name, n, err)
+		}
+		if _, err := cache.Incr("a", 1, 0); err == nil {
+			t.Errorf("%s: expected not numeric error", name)
+		}
+
+		// struct with interface field holding slice is not comparable
+		type holder struct{ V interface{} }
+		cache.Set("h", holder{[]int{1}}, 0)
+		if ok, err := cache.CompareAndSwap("h", holder{[]int{1}}, 1, 0); ok || err != nil {
+			t.Errorf("%s: expected not swapped,got:%v,%v", name, ok, err)
+		}
+	}
+}
+
+func TestBulk(t *testing.T) {
+	backends := map[string]BaseCache{
+		"lru": lru.NewLruCache(),
+		"ttl": ttl.NewTtlCache(),
+	}
+	for name, backend := range backends {
+		cache := NewCache(WithBaseCache(backend))
+		cache.SetMulti(map[string]interface{}{
+			"tenant1:a": 1,
+			"tenant1:b": 2,
+			"tenant2:a": 3,
+		}, time.Minute)
+		cache.Set("expired", 4, time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		if keys := cache.Keys(); len(keys) != 3 {
+			t.Errorf("%s: expected 3 keys,got:%v", name, keys)
+		}
+		values := cache.GetMulti([]string{"tenant1:a", "tenant2:a", "missing"})
+		if len(values) != 2 || values["tenant2:a"] != 3 {
+			t.Errorf("%s: expected 2 values,got:%v", name, values)
+		}
+		if n := cache.DeletePrefix("tenant1:"); n != 2 {
+			t.Errorf("%s: expected:2,got:%d", name, n)
+		}
+		cache.DeleteMulti([]string{"tenant2:a", "expired"})
+		if n := cache.Len(); n != 0 {
+			t.Errorf("%s: expected:0,got:%d", name, n)
+		}
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	shared := lru.NewLruCache()
+	users := NewCache(WithBaseCache(shared), WithNamespace("users"))
+	orders := NewCache(WithBaseCache(shared), WithNamespace("orders"))
+	users.Set("1", "alice", 0)
+	orders.Set("1", "order", 0)
+	if v, _ := users.GetString("1"); v != "alice" {
+		t.Errorf("expected:alice,got:%v", v)
+	}
+	if _, ok := shared.Get("users:1"); !ok {
+		t.Error("expected key prefixed by namespace")
+	}
+	if keys := users.Keys(); len(keys) != 1 || keys[0] != "1" {
+		t.Errorf("expected:[1],got:%v", keys)
+	}
+	if n, err := users.Incr("visits", 1, 0); n != 1 || err != nil {
+		t.Errorf("expected:1,got:%v,%v", n, err)
+	}
+	users.Clear()
+	if v, _ := orders.GetString("1"); v != "order" {
+		t.Errorf("expected:order,got:%v", v)
+	}
+	if stats := users.Stats(); stats.Sets != 2 || stats.Hits != 1 || stats.Items != 0 {
+		t.Errorf("unexpected users stats:%+v", stats)
+	}
+	if stats := orders.Stats(); stats.Sets != 1 || stats.Hits != 1 || stats.Items != 1 {
+		t.Errorf("unexpected orders stats:%+v", stats)
+	}
+
+	// namespace containing separator does not collide
+	a := NewCache(WithBaseCache(shared), WithNamespace("a"))
+	ab := NewCache(WithBaseCache(shared), WithNamespace("a:b"))
+	a.Set("b:c", 1, 0)
+	ab.Set("c", 2, 0)
+	if v, _ := a.GetInt("b:c"); v != 1 {
+		t.Errorf("expected:1,got:%v", v)
+	}
+	if keys := a.Keys(); len(keys) != 1 || keys[0] != "b:c" {
+		t.Errorf("expected:[b:c],got:%v", keys)
+	}
+	a.Clear()
+	if v, ok := ab.GetInt("c"); !ok || v != 2 {
+		t.Errorf("expected:2,got:%v,%v", v, ok)
+	}
+}
+
+func TestSoftTTL(t *testing.T) {
+	var loads int32
+	cache := NewCache(
+		Loader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+			n := atomic.AddInt32(&loads, 1)
+			time.Sleep(time.Millisecond * 20)
+			return n, time.Millisecond * 300, nil
+		}),
+		SoftTTL(time.Millisecond*50),
+	)
+	if v, err := cache.GetOrLoad(context.Background(), "a"); v != int32(1) || err != nil {
+		t.Fatalf("expected:1,got:%v,%v", v, err)
+	}
+	time.Sleep(time.Millisecond * 60)
+	for i := 0; i < 10; i++ {
+		if v, ok := cache.Get("a"); !ok || v != int32(1) {
+			t.Errorf("expected stale 1,got:%v,%v", v, ok)
+		}
+	}
+	time.Sleep(time.Millisecond * 40)
+	if v, ok := cache.Get("a"); !ok || v != int32(2) {
+		t.Errorf("expected refreshed 2,got:%v,%v", v, ok)
+	}
+	if n := atomic.LoadInt32(&loads); n != 2 {
+		t.Errorf("expected:2 loads,got:%d", n)
+	}
+	time.Sleep(time.Millisecond * 400)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected a expired after hard ttl")
+	}
+}
+
+func TestSoftTTLRefreshError(t *testing.T) {
+	var loads int32
+	cache := NewCache(
+		Loader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+			if atomic.AddInt32(&loads, 1) > 1 {
+				return nil, 0, errors.New("backend down")
+			}
+			return 1, time.Minute, nil
+		}),
+		SoftTTL(time.Millisecond*100),
+	)
+	if _, err := cache.GetOrLoad(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 110)
+	for deadline := time.Now().Add(time.Millisecond * 50); time.Now().Before(deadline); {
+		if v, ok := cache.Get("a"); !ok || v != 1 {
+			t.Fatalf("expected stale 1,got:%v,%v", v, ok)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// one refresh failed, the next one waits for another SoftTTL
+	if n := atomic.LoadInt32(&loads); n != 2 {
+		t.Errorf("expected failing refresh postponed,got:%d loads", n)
+	}
+}
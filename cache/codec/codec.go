@@ -0,0 +1,104 @@
+// Package codec serialize cache values, shared by cache backends which
+// store values out of process memory
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Codec marshal value into bytes, and unmarshal bytes into pointer
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// Gob use encoding/gob, concrete types stored in interface{} should
+	// be registered by gob.Register
+	Gob Codec = gobCodec{}
+	// JSON use json-iterator compatible with encoding/json, numbers
+	// stored in interface{} are decoded as float64
+	JSON Codec = jsonCodec{}
+	// Raw store []byte and string as is, values stored in interface{}
+	// are decoded as []byte
+	Raw Codec = rawCodec{}
+)
+
+// ErrNotBytes is returned by Raw when value is not []byte or string
+var ErrNotBytes = errors.New("codec: raw value is not []byte or string")
+
+// Encode marshal v by c
+func Encode[T any](c Codec, v T) ([]byte, error) {
+	return c.Marshal(&v)
+}
+
+// Decode unmarshal data by c into T
+func Decode[T any](c Codec, data []byte) (T, error) {
+	var v T
+	err := c.Unmarshal(data, &v)
+	return v, err
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case *[]byte:
+		return *v, nil
+	case string:
+		return []byte(v), nil
+	case *string:
+		return []byte(*v), nil
+	case *interface{}:
+		return rawCodec{}.Marshal(*v)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrNotBytes, v)
+	}
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *[]byte:
+		*v = append([]byte(nil), data...)
+	case *string:
+		*v = string(data)
+	case *interface{}:
+		*v = append([]byte(nil), data...)
+	default:
+		return fmt.Errorf("%w: %T", ErrNotBytes, v)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestCodec(t *testing.T) {
+	for name, c := range map[string]Codec{"gob": Gob, "json": JSON} {
+		data, err := Encode(c, user{Name: "hao", Age: 18})
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := Decode[user](c, data)
+		if err != nil || u.Name != "hao" || u.Age != 18 {
+			t.Errorf("%s: expected:hao 18,got:%+v,%v", name, u, err)
+		}
+	}
+
+	data, err := Encode(Raw, "hello")
+	if err != nil || string(data) != "hello" {
+		t.Errorf("expected:hello,got:%s,%v", data, err)
+	}
+	b, err := Decode[interface{}](Raw, data)
+	if err != nil || !bytes.Equal(b.([]byte), data) {
+		t.Errorf("expected:hello,got:%v,%v", b, err)
+	}
+	if _, err := Encode(Raw, 1); err == nil {
+		t.Error("expected ErrNotBytes")
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"github.com/haormj/util/cache/evict"
+)
+
+// EvictReason is why an entry is removed from cache
+type EvictReason = evict.Reason
+
+const (
+	EvictExpired  = evict.Expired
+	EvictCapacity = evict.Capacity
+	EvictDeleted  = evict.Deleted
+	EvictCleared  = evict.Cleared
+)
+
+// EvictFunc is called after an entry is removed from cache
+type EvictFunc = evict.Func[string, interface{}]
+
+// evictNotifier is implemented by BaseCache which is able to
+// report removed entries, such as lru.LruCache and ttl.TtlCache
+type evictNotifier interface {
+	OnEvict(evict.Func[string, interface{}])
+}
+
+// setOnEvict plumb f into baseCache, f is added to functions set by
+// other Cache sharing baseCache instead of replacing them
+func setOnEvict(baseCache BaseCache, f EvictFunc) {
+	notifier, ok := baseCache.(evictNotifier)
+	if !ok {
+		return
+	}
+	notifier.OnEvict(f)
+}
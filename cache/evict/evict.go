@@ -0,0 +1,51 @@
+// Package evict define why an entry leaves the cache, shared by
+// cache backends
+package evict
+
+// Reason is why an entry is removed from cache
+type Reason int
+
+const (
+	// Expired means entry is removed after its ttl
+	Expired Reason = iota + 1
+	// Capacity means entry is removed to make room for new entry
+	Capacity
+	// Deleted means entry is removed by Delete
+	Deleted
+	// Cleared means entry is removed by Clear
+	Cleared
+)
+
+func (r Reason) String() string {
+	switch r {
+	case Expired:
+		return "expired"
+	case Capacity:
+		return "capacity"
+	case Deleted:
+		return "deleted"
+	case Cleared:
+		return "cleared"
+	default:
+		return "unknown"
+	}
+}
+
+// Func is called after an entry is removed from cache,
+// it is not called when value is replaced by Set
+type Func[K comparable, V any] func(key K, value V, reason Reason)
+
+// Chain return Func which call a then b, so a cache is able to
+// report removed entries to more than one subscriber
+func Chain[K comparable, V any](a, b Func[K, V]) Func[K, V] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return func(key K, value V, reason Reason) {
+		a(key, value, reason)
+		b(key, value, reason)
+	}
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/spf13/cast"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// GetAs decode cached value of key into v. value which is T or *T is
+// assigned directly, []byte and string are decoded as json, other
+// values such as map[string]interface{} are converted through json
+func GetAs[T any](c BaseCache, key string, v *T) (bool, error) {
+	value, ok := c.Get(key)
+	if !ok {
+		return false, nil
+	}
+	switch value := value.(type) {
+	case T:
+		*v = value
+	case *T:
+		if value == nil {
+			return true, fmt.Errorf("cache: value of %s is nil", key)
+		}
+		*v = *value
+	case []byte:
+		return true, json.Unmarshal(value, v)
+	case string:
+		return true, json.Unmarshal([]byte(value), v)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return true, err
+		}
+		return true, json.Unmarshal(data, v)
+	}
+	return true, nil
+}
+
+func (dc defaultCache) GetInt(key string) (int, bool) {
+	v, ok := dc.Get(key)
+	value := cast.ToInt(v)
+	return value, ok
+}
+
+func (dc defaultCache) GetIntE(key string) (int, bool, error) {
+	v, ok := dc.Get(key)
+	value, err := cast.ToIntE(v)
+	return value, ok, err
+}
+
+func (dc defaultCache) GetInt64(key string) (int64, bool) {
+	v, ok := dc.Get(key)
+	value := cast.ToInt64(v)
+	return value, ok
+}
+
+func (dc defaultCache) GetInt64E(key string) (int64, bool, error) {
+	v, ok := dc.Get(key)
+	value, err := cast.ToInt64E(v)
+	return value, ok, err
+}
+
+func (dc defaultCache) GetBool(key string) (bool, bool) {
+	v, ok := dc.Get(key)
+	value := cast.ToBool(v)
+	return value, ok
+}
+
+func (dc defaultCache) GetBoolE(key string) (bool, bool, error) {
+	v, ok := dc.Get(key)
+	value, err := cast.ToBoolE(v)
+	return value, ok, err
+}
+
+func (dc defaultCache) GetFloat64(key string) (float64, bool) {
+	v, ok := dc.Get(key)
+	value := cast.ToFloat64(v)
+	return value, ok
+}
+
+func (dc defaultCache) GetFloat64E(key string) (float64, bool, error) {
+	v, ok := dc.Get(key)
+	value, err := cast.ToFloat64E(v)
+	return value, ok, err
+}
+
+func (dc defaultCache) GetDuration(key string) (time.Duration, bool) {
+	v, ok := dc.Get(key)
+	value := cast.ToDuration(v)
+	return value, ok
+}
+
+func (dc defaultCache) GetDurationE(key string) (time.Duration, bool, error) {
+	v, ok := dc.Get(key)
+	value, err := cast.ToDurationE(v)
+	return value, ok, err
+}
+
+func (dc defaultCache) GetTime(key string) (time.Time, bool) {
+	v, ok := dc.Get(key)
+	value := cast.ToTime(v)
+	return value, ok
+}
+
+func (dc defaultCache) GetTimeE(key string) (time.Time, bool, error) {
+	v, ok := dc.Get(key)
+	value, err := cast.ToTimeE(v)
+	return value, ok, err
+}
+
+func (dc defaultCache) GetStringSlice(key string) ([]string, bool) {
+	v, ok := dc.Get(key)
+	value := cast.ToStringSlice(v)
+	return value, ok
+}
+
+func (dc defaultCache) GetStringSliceE(key string) ([]string, bool, error) {
+	v, ok := dc.Get(key)
+	value, err := cast.ToStringSliceE(v)
+	return value, ok, err
+}
+
+func (dc defaultCache) GetStringMap(key string) (map[string]interface{}, bool) {
+	v, ok := dc.Get(key)
+	value := cast.ToStringMap(v)
+	return value, ok
+}
+
+func (dc defaultCache) GetStringMapE(key string) (map[string]interface{}, bool, error) {
+	v, ok := dc.Get(key)
+	value, err := cast.ToStringMapE(v)
+	return value, ok, err
+}
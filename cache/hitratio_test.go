@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/haormj/util/cache/arc"
+	"github.com/haormj/util/cache/lfu"
+	"github.com/haormj/util/cache/lru"
+	"github.com/haormj/util/cache/tinylfu"
+)
+
+const (
+	hitRatioCapacity = 1000
+	hitRatioKeys     = 100000
+)
+
+var hitRatioBackends = []struct {
+	name string
+	new  func() BaseCache
+}{
+	{"lru", func() BaseCache { return lru.NewLruCache(lru.MaxEntries(hitRatioCapacity)) }},
+	{"lfu", func() BaseCache { return lfu.NewLfuCache(lfu.MaxEntries(hitRatioCapacity)) }},
+	{"arc", func() BaseCache { return arc.NewArcCache(arc.MaxEntries(hitRatioCapacity)) }},
+	{"tinylfu", func() BaseCache { return tinylfu.NewTinyLfuCache(tinylfu.MaxEntries(hitRatioCapacity)) }},
+}
+
+// zipfTrace generate n keys following zipf distribution
+func zipfTrace(n int) []string {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.01, 1, hitRatioKeys-1)
+	trace := make([]string, n)
+	for i := range trace {
+		trace[i] = strconv.FormatUint(z.Uint64(), 10)
+	}
+	return trace
+}
+
+// scanTrace is zipfTrace interleaved with sequential scans of keys
+// never seen before
+func scanTrace(n int) []string {
+	trace := zipfTrace(n)
+	scan := 0
+	for i := 0; i < len(trace); i += 10 * hitRatioCapacity {
+		for j := i; j < i+2*hitRatioCapacity && j < len(trace); j++ {
+			trace[j] = "scan" + strconv.Itoa(scan)
+			scan++
+		}
+	}
+	return trace
+}
+
+func benchmarkHitRatio(b *testing.B, trace func(int) []string) {
+	for _, backend := range hitRatioBackends {
+		b.Run(backend.name, func(b *testing.B) {
+			keys := trace(b.N)
+			c := backend.new()
+			var hits int
+			b.ResetTimer()
+			for _, key := range keys {
+				if _, ok := c.Get(key); ok {
+					hits++
+					continue
+				}
+				c.Set(key, key, 0)
+			}
+			b.ReportMetric(float64(hits)*100/float64(len(keys)), "hit%")
+		})
+	}
+}
+
+func BenchmarkHitRatioZipf(b *testing.B) {
+	benchmarkHitRatio(b, zipfTrace)
+}
+
+func BenchmarkHitRatioZipfScan(b *testing.B) {
+	benchmarkHitRatio(b, scanTrace)
+}
@@ -0,0 +1,57 @@
+package bounded
+
+import (
+	"time"
+
+	"github.com/haormj/util/cache/internal/entry"
+)
+
+// store adapt Cache to entry.Store, so atomic operations are shared
+// with other backends
+type store[K comparable, V any] struct {
+	*Cache[K, V]
+}
+
+func (s store[K, V]) Lock() {
+	s.mu.Lock()
+}
+
+func (s store[K, V]) Unlock() {
+	s.unlock()
+}
+
+func (s store[K, V]) Lookup(key K) (entry.Item[V], bool) {
+	return s.lookup(key)
+}
+
+func (s store[K, V]) Set(key K, value V, expiration int64) {
+	s.stats.Set()
+	s.set(key, value, expiration)
+}
+
+// Add set value only if key is missing, report whether it is set
+func (c *Cache[K, V]) Add(key K, value V, ttl time.Duration) bool {
+	return entry.Add[K, V](store[K, V]{c}, key, value, ttl)
+}
+
+// Replace set value only if key exists, report whether it is set
+func (c *Cache[K, V]) Replace(key K, value V, ttl time.Duration) bool {
+	return entry.Replace[K, V](store[K, V]{c}, key, value, ttl)
+}
+
+// CompareAndSwap set new only if current value of key equals old,
+// report whether it is set
+func (c *Cache[K, V]) CompareAndSwap(key K, old, new V, ttl time.Duration) bool {
+	return entry.CompareAndSwap[K, V](store[K, V]{c}, key, old, new, ttl)
+}
+
+// Incr add delta to numeric value of key and return the result.
+// missing key is set to delta with ttl, otherwise its expiration is kept
+func (c *Cache[K, V]) Incr(key K, delta int64, ttl time.Duration) (int64, error) {
+	return entry.Incr[K, V](store[K, V]{c}, key, delta, ttl)
+}
+
+// Decr subtract delta from numeric value of key, same as Incr
+func (c *Cache[K, V]) Decr(key K, delta int64, ttl time.Duration) (int64, error) {
+	return entry.Decr[K, V](store[K, V]{c}, key, delta, ttl)
+}
@@ -0,0 +1,188 @@
+// Package bounded implements a cache bounded by entry count, eviction
+// is decided by a Policy, so eviction algorithms only track keys
+package bounded
+
+import (
+	"sync"
+	"time"
+
+	"github.com/haormj/util/cache/evict"
+	"github.com/haormj/util/cache/internal/entry"
+	"github.com/haormj/util/cache/stats"
+)
+
+// Policy decide which keys to keep, it is not safe for concurrent use
+type Policy[K comparable] interface {
+	// Add record new key, and return keys to evict, which may
+	// include key itself when it is not admitted
+	Add(key K) []K
+	// Access record hit of key
+	Access(key K)
+	// Miss record lookup of missing key
+	Miss(key K)
+	// Remove forget key
+	Remove(key K)
+	// Clear forget all keys
+	Clear()
+}
+
+// Cache is safe for concurrent use, implements cache.TypedCache
+type Cache[K comparable, V any] struct {
+	mu     sync.Mutex
+	policy Policy[K]
+	items  map[K]entry.Item[V]
+	stats  stats.Counter
+	// evictions are reported after mu is released
+	evictions entry.Evictions[K, V]
+}
+
+func New[K comparable, V any](policy Policy[K]) *Cache[K, V] {
+	return &Cache[K, V]{
+		policy: policy,
+		items:  make(map[K]entry.Item[V]),
+	}
+}
+
+// Set add value to cache, ttl <= 0 means never expire
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.stats.Set()
+	c.mu.Lock()
+	c.set(key, value, entry.Expiration(ttl))
+	c.unlock()
+}
+
+// set is Set with mu held
+func (c *Cache[K, V]) set(key K, value V, expiration int64) {
+	_, exist := c.items[key]
+	c.items[key] = entry.Item[V]{Value: value, Expiration: expiration}
+	if exist {
+		c.policy.Access(key)
+		return
+	}
+	for _, k := range c.policy.Add(key) {
+		c.stats.Evict()
+		c.remove(k, evict.Capacity)
+	}
+}
+
+// Get lookup value from cache, expired entry is removed
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	i, ok := c.lookup(key)
+	if !ok {
+		c.stats.Miss()
+		return i.Value, false
+	}
+	c.policy.Access(key)
+	c.stats.Hit()
+	return i.Value, true
+}
+
+// lookup find unexpired item with mu held, without counting hit
+func (c *Cache[K, V]) lookup(key K) (entry.Item[V], bool) {
+	i, ok := c.items[key]
+	if !ok {
+		c.policy.Miss(key)
+		return entry.Item[V]{}, false
+	}
+	if i.Expired(time.Now().UnixNano()) {
+		c.policy.Remove(key)
+		c.stats.Expire()
+		c.remove(key, evict.Expired)
+		return entry.Item[V]{}, false
+	}
+	return i, true
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.stats.Delete()
+	c.mu.Lock()
+	if _, ok := c.items[key]; ok {
+		c.policy.Remove(key)
+		c.remove(key, evict.Deleted)
+	}
+	c.unlock()
+}
+
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	for key := range c.items {
+		c.remove(key, evict.Cleared)
+	}
+	c.policy.Clear()
+	c.unlock()
+}
+
+// DeleteExpired remove all expired entries
+func (c *Cache[K, V]) DeleteExpired() {
+	now := time.Now().UnixNano()
+	c.mu.Lock()
+	for key, i := range c.items {
+		if i.Expired(now) {
+			c.policy.Remove(key)
+			c.stats.Expire()
+			c.remove(key, evict.Expired)
+		}
+	}
+	c.unlock()
+}
+
+// Keys return unexpired keys, in no particular order
+func (c *Cache[K, V]) Keys() []K {
+	now := time.Now().UnixNano()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]K, 0, len(c.items))
+	for key, i := range c.items {
+		if !i.Expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len return number of entries, it may include expired entries which
+// are not removed yet
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// OnEvict add function called after entry is removed from cache,
+// functions added before are still called
+func (c *Cache[K, V]) OnEvict(f evict.Func[K, V]) {
+	c.mu.Lock()
+	c.evictions.OnEvict(f)
+	c.mu.Unlock()
+}
+
+// Stats return counters and current item count
+func (c *Cache[K, V]) Stats() stats.Stats {
+	c.mu.Lock()
+	items := len(c.items)
+	c.mu.Unlock()
+	return c.stats.Snapshot(int64(items))
+}
+
+// ResetStats set all counters to zero
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+// remove delete item which is already forgot by policy
+func (c *Cache[K, V]) remove(key K, reason evict.Reason) {
+	i, ok := c.items[key]
+	if !ok {
+		return
+	}
+	delete(c.items, key)
+	c.evictions.Add(key, i.Value, reason)
+}
+
+// unlock release mu, then report evicted entries, so onEvict is able
+// to call cache again
+func (c *Cache[K, V]) unlock() {
+	c.evictions.Unlock(c.mu.Unlock)
+}
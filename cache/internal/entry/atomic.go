@@ -0,0 +1,82 @@
+package entry
+
+import (
+	"time"
+
+	"github.com/haormj/util/cache/internal/value"
+)
+
+// Store is cache whose lock is held by atomic operations around
+// Lookup and Set, so they are implemented once for all backends
+type Store[K comparable, V any] interface {
+	Lock()
+	// Unlock release lock, and report evicted entries if any
+	Unlock()
+	// Lookup find unexpired item, without counting hit or miss
+	Lookup(key K) (Item[V], bool)
+	// Set store value with expiration, and count it
+	Set(key K, value V, expiration int64)
+}
+
+// Add set value only if key is missing, report whether it is set
+func Add[K comparable, V any](s Store[K, V], key K, value V, ttl time.Duration) bool {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.Lookup(key); ok {
+		return false
+	}
+	s.Set(key, value, Expiration(ttl))
+	return true
+}
+
+// Replace set value only if key exists, report whether it is set
+func Replace[K comparable, V any](s Store[K, V], key K, value V, ttl time.Duration) bool {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.Lookup(key); !ok {
+		return false
+	}
+	s.Set(key, value, Expiration(ttl))
+	return true
+}
+
+// CompareAndSwap set new only if current value of key equals old,
+// report whether it is set
+func CompareAndSwap[K comparable, V any](s Store[K, V], key K, old, new V, ttl time.Duration) bool {
+	s.Lock()
+	defer s.Unlock()
+	i, ok := s.Lookup(key)
+	if !ok || !value.Equal(i.Value, old) {
+		return false
+	}
+	s.Set(key, new, Expiration(ttl))
+	return true
+}
+
+// Incr add delta to numeric value of key and return the result.
+// missing key is set to delta with ttl, otherwise its expiration is kept
+func Incr[K comparable, V any](s Store[K, V], key K, delta int64, ttl time.Duration) (int64, error) {
+	return update(s, key, delta, ttl, value.Incr[V])
+}
+
+// Decr subtract delta from numeric value of key, same as Incr
+func Decr[K comparable, V any](s Store[K, V], key K, delta int64, ttl time.Duration) (int64, error) {
+	return update(s, key, delta, ttl, value.Decr[V])
+}
+
+// update apply op to value of key, it is Incr or Decr
+func update[K comparable, V any](s Store[K, V], key K, delta int64, ttl time.Duration,
+	op func(V, int64) (V, int64, error)) (int64, error) {
+	s.Lock()
+	defer s.Unlock()
+	i, ok := s.Lookup(key)
+	if !ok {
+		i.Expiration = Expiration(ttl)
+	}
+	v, n, err := op(i.Value, delta)
+	if err != nil {
+		return 0, err
+	}
+	s.Set(key, v, i.Expiration)
+	return n, nil
+}
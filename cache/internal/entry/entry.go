@@ -0,0 +1,66 @@
+// Package entry implements expiration and eviction report of entries,
+// shared by in-memory cache backends
+package entry
+
+import (
+	"time"
+
+	"github.com/haormj/util/cache/evict"
+)
+
+// Item is value stored by cache with its expiration and cost
+type Item[V any] struct {
+	Value V
+	// Expiration is unix nano, zero means never expire
+	Expiration int64
+	Cost       int64
+}
+
+func (i Item[V]) Expired(now int64) bool {
+	return i.Expiration > 0 && now > i.Expiration
+}
+
+// Expiration convert ttl to unix nano, zero means never expire
+func Expiration(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return time.Now().Add(ttl).UnixNano()
+}
+
+// Evictions buffer removed entries while lock of cache is held, and
+// report them after the lock is released, so OnEvict is able to call
+// cache again. it is guarded by lock of cache
+type Evictions[K comparable, V any] struct {
+	onEvict evict.Func[K, V]
+	evicted []evicted[K, V]
+}
+
+type evicted[K comparable, V any] struct {
+	key    K
+	value  V
+	reason evict.Reason
+}
+
+// OnEvict add function called after entry is removed from cache
+func (e *Evictions[K, V]) OnEvict(f evict.Func[K, V]) {
+	e.onEvict = evict.Chain(e.onEvict, f)
+}
+
+// Add buffer removed entry, it does nothing without OnEvict
+func (e *Evictions[K, V]) Add(key K, value V, reason evict.Reason) {
+	if e.onEvict != nil {
+		e.evicted = append(e.evicted, evicted[K, V]{key: key, value: value, reason: reason})
+	}
+}
+
+// Unlock call unlock to release lock of cache, then report buffered
+// entries
+func (e *Evictions[K, V]) Unlock(unlock func()) {
+	evicted, onEvict := e.evicted, e.onEvict
+	e.evicted = nil
+	unlock()
+	for _, v := range evicted {
+		onEvict(v.key, v.value, v.reason)
+	}
+}
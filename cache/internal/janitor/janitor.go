@@ -0,0 +1,42 @@
+// Package janitor remove expired entries of in-memory caches in
+// background, shared by cache backends
+package janitor
+
+import (
+	"time"
+)
+
+type Janitor struct {
+	interval time.Duration
+	stop     chan struct{}
+}
+
+// Run call deleteExpired every interval until Stop is called.
+// deleteExpired should not hold the owner of cache, so the owner can be
+// garbage collected and its finalizer stop the janitor, same as go-cache
+func Run(interval time.Duration, deleteExpired func()) *Janitor {
+	j := &Janitor{
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+	go j.run(deleteExpired)
+	return j
+}
+
+func (j *Janitor) run(deleteExpired func()) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			deleteExpired()
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// Stop stop calling deleteExpired, it should be called only once
+func (j *Janitor) Stop() {
+	close(j.stop)
+}
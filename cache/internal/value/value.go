@@ -0,0 +1,145 @@
+// Package value implements comparison and arithmetic on cached values,
+// shared by cache backends
+package value
+
+import (
+	"errors"
+	"math"
+	"reflect"
+)
+
+var (
+	// ErrNotNumeric is returned by Incr when value is not numeric
+	ErrNotNumeric = errors.New("cache: value is not numeric")
+	// ErrOverflow is returned by Incr when result is out of range of
+	// value, such as decreasing unsigned value below zero
+	ErrOverflow = errors.New("cache: increment or decrement would overflow")
+)
+
+// Equal report whether a == b, uncomparable values are never equal
+func Equal[V any](a, b V) bool {
+	return equal(a, b)
+}
+
+func equal(a, b interface{}) (eq bool) {
+	// Comparable is true for structs and arrays with interface fields,
+	// == still panic when those fields hold uncomparable values
+	defer func() {
+		if recover() != nil {
+			eq = false
+		}
+	}()
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb || !ta.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// Incr add delta to numeric v and keep its type, nil interface is
+// treated as int64. the result is also returned as int64, ErrOverflow
+// is returned when it does not fit in type of v or int64
+func Incr[V any](v V, delta int64) (V, int64, error) {
+	nv, n, err := add(v, delta)
+	if err != nil {
+		return v, 0, err
+	}
+	result, ok := nv.(V)
+	if !ok {
+		return v, 0, ErrNotNumeric
+	}
+	return result, n, nil
+}
+
+// Decr subtract delta from numeric v, same as Incr
+func Decr[V any](v V, delta int64) (V, int64, error) {
+	if delta != math.MinInt64 {
+		return Incr(v, -delta)
+	}
+	// -delta overflow int64, subtract it in two steps
+	nv, _, err := Incr(v, math.MaxInt64)
+	if err != nil {
+		return v, 0, err
+	}
+	nv, n, err := Incr(nv, 1)
+	if err != nil {
+		return v, 0, err
+	}
+	return nv, n, nil
+}
+
+func add(v interface{}, delta int64) (interface{}, int64, error) {
+	switch n := v.(type) {
+	case nil:
+		return delta, delta, nil
+	case int:
+		r, err := addInt(int64(n), delta, math.MinInt, math.MaxInt)
+		return int(r), r, err
+	case int8:
+		r, err := addInt(int64(n), delta, math.MinInt8, math.MaxInt8)
+		return int8(r), r, err
+	case int16:
+		r, err := addInt(int64(n), delta, math.MinInt16, math.MaxInt16)
+		return int16(r), r, err
+	case int32:
+		r, err := addInt(int64(n), delta, math.MinInt32, math.MaxInt32)
+		return int32(r), r, err
+	case int64:
+		r, err := addInt(n, delta, math.MinInt64, math.MaxInt64)
+		return r, r, err
+	case uint:
+		r, err := addUint(uint64(n), delta, math.MaxUint)
+		return uint(r), int64(r), err
+	case uint8:
+		r, err := addUint(uint64(n), delta, math.MaxUint8)
+		return uint8(r), int64(r), err
+	case uint16:
+		r, err := addUint(uint64(n), delta, math.MaxUint16)
+		return uint16(r), int64(r), err
+	case uint32:
+		r, err := addUint(uint64(n), delta, math.MaxUint32)
+		return uint32(r), int64(r), err
+	case uint64:
+		r, err := addUint(n, delta, math.MaxUint64)
+		return r, int64(r), err
+	case float32:
+		n += float32(delta)
+		return n, int64(n), nil
+	case float64:
+		n += float64(delta)
+		return n, int64(n), nil
+	default:
+		return v, 0, ErrNotNumeric
+	}
+}
+
+// addInt add delta to n, the result must be in [min, max]
+func addInt(n, delta, min, max int64) (int64, error) {
+	if delta > 0 && n > max-delta || delta < 0 && n < min-delta {
+		return 0, ErrOverflow
+	}
+	return n + delta, nil
+}
+
+// addUint add delta to n, the result must be in [0, max] and fit in
+// int64, because it is also returned as int64
+func addUint(n uint64, delta int64, max uint64) (uint64, error) {
+	if max > math.MaxInt64 {
+		max = math.MaxInt64
+	}
+	if delta >= 0 {
+		if n > max || uint64(delta) > max-n {
+			return 0, ErrOverflow
+		}
+		return n + uint64(delta), nil
+	}
+	// -(delta+1)+1 does not overflow when delta is math.MinInt64
+	d := uint64(-(delta + 1)) + 1
+	if d > n {
+		return 0, ErrOverflow
+	}
+	return n - d, nil
+}
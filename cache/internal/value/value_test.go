@@ -0,0 +1,49 @@
+package value
+
+import (
+	"math"
+	"testing"
+)
+
+type holder struct {
+	V interface{}
+}
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b  interface{}
+		equal bool
+	}{
+		{nil, nil, true},
+		{1, 1, true},
+		{1, int64(1), false},
+		{[]int{1}, []int{1}, false},
+		{holder{1}, holder{1}, true},
+		{holder{[]int{1}}, holder{[]int{1}}, false},
+		{holder{map[string]int{}}, holder{1}, false},
+		{[1]interface{}{[]int{1}}, [1]interface{}{[]int{1}}, false},
+	}
+	for _, c := range cases {
+		if eq := Equal(c.a, c.b); eq != c.equal {
+			t.Errorf("%v == %v: expected:%v,got:%v", c.a, c.b, c.equal, eq)
+		}
+	}
+}
+
+func TestIncrOverflow(t *testing.T) {
+	if _, _, err := Decr(uint(1), 2); err != ErrOverflow {
+		t.Errorf("expected:%v,got:%v", ErrOverflow, err)
+	}
+	if _, _, err := Incr(int8(math.MaxInt8), 1); err != ErrOverflow {
+		t.Errorf("expected:%v,got:%v", ErrOverflow, err)
+	}
+	if _, _, err := Decr(int64(0), math.MinInt64); err != ErrOverflow {
+		t.Errorf("expected:%v,got:%v", ErrOverflow, err)
+	}
+	if v, n, err := Decr(int64(-1), math.MinInt64); err != nil || v != math.MaxInt64 || n != math.MaxInt64 {
+		t.Errorf("expected:%d,got:%v,%v,%v", int64(math.MaxInt64), v, n, err)
+	}
+	if v, n, err := Decr(uint8(3), 3); err != nil || v != 0 || n != 0 {
+		t.Errorf("expected:0,got:%v,%v,%v", v, n, err)
+	}
+}
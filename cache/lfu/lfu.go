@@ -0,0 +1,133 @@
+// Package lfu implements cache which evict the least frequently used
+// entry, entries with the same frequency are evicted in lru order.
+// entries touched once by a scan are evicted before frequent ones
+package lfu
+
+import (
+	"container/list"
+	"runtime"
+
+	"github.com/haormj/util/cache/internal/bounded"
+	"github.com/haormj/util/cache/internal/janitor"
+)
+
+// LfuCache implements cache.BaseCache by using memory
+type LfuCache struct {
+	*Cache[string, interface{}]
+}
+
+func NewLfuCache(opts ...Option) *LfuCache {
+	lfuCache := &LfuCache{
+		Cache: New[string, interface{}](opts...),
+	}
+	return lfuCache
+}
+
+// Cache is a type-safe lfu cache, implements cache.TypedCache
+type Cache[K comparable, V any] struct {
+	*bounded.Cache[K, V]
+	// janitor only hold bounded.Cache, so Cache can be garbage
+	// collected and the finalizer stop the janitor
+	janitor *janitor.Janitor
+}
+
+func New[K comparable, V any](opts ...Option) *Cache[K, V] {
+	option := newOptions(opts...)
+
+	c := &Cache[K, V]{
+		Cache: bounded.New[K, V](newPolicy[K](option.MaxEntries)),
+	}
+	if option.CleanupInterval > 0 {
+		c.janitor = janitor.Run(option.CleanupInterval, c.Cache.DeleteExpired)
+		runtime.SetFinalizer(c, stopJanitor[K, V])
+	}
+	return c
+}
+
+func stopJanitor[K comparable, V any](c *Cache[K, V]) {
+	c.janitor.Stop()
+}
+
+// policy is O(1) lfu, freqs is ordered by frequency ascending, and
+// keys of every frequency are ordered by recency
+type policy[K comparable] struct {
+	maxEntries int
+	freqs      *list.List
+	entries    map[K]*entry[K]
+}
+
+type freqNode[K comparable] struct {
+	freq int
+	keys *list.List
+}
+
+type entry[K comparable] struct {
+	freq *list.Element
+	key  *list.Element
+}
+
+func newPolicy[K comparable](maxEntries int) *policy[K] {
+	return &policy[K]{
+		maxEntries: maxEntries,
+		freqs:      list.New(),
+		entries:    make(map[K]*entry[K]),
+	}
+}
+
+func (p *policy[K]) Add(key K) []K {
+	var evicted []K
+	if len(p.entries) >= p.maxEntries {
+		node := p.freqs.Front().Value.(*freqNode[K])
+		victim := node.keys.Back().Value.(K)
+		p.Remove(victim)
+		evicted = append(evicted, victim)
+	}
+
+	front := p.freqs.Front()
+	if front == nil || front.Value.(*freqNode[K]).freq != 1 {
+		front = p.freqs.PushFront(&freqNode[K]{freq: 1, keys: list.New()})
+	}
+	p.entries[key] = &entry[K]{
+		freq: front,
+		key:  front.Value.(*freqNode[K]).keys.PushFront(key),
+	}
+	return evicted
+}
+
+func (p *policy[K]) Access(key K) {
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+	node := e.freq.Value.(*freqNode[K])
+	next := e.freq.Next()
+	if next == nil || next.Value.(*freqNode[K]).freq != node.freq+1 {
+		next = p.freqs.InsertAfter(&freqNode[K]{freq: node.freq + 1, keys: list.New()}, e.freq)
+	}
+	node.keys.Remove(e.key)
+	if node.keys.Len() == 0 {
+		p.freqs.Remove(e.freq)
+	}
+	e.freq = next
+	e.key = next.Value.(*freqNode[K]).keys.PushFront(key)
+}
+
+func (p *policy[K]) Miss(key K) {}
+
+func (p *policy[K]) Remove(key K) {
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+	node := e.freq.Value.(*freqNode[K])
+	node.keys.Remove(e.key)
+	if node.keys.Len() == 0 {
+		p.freqs.Remove(e.freq)
+	}
+	delete(p.entries, key)
+}
+
+func (p *policy[K]) Clear() {
+	p.freqs.Init()
+	p.entries = make(map[K]*entry[K])
+}
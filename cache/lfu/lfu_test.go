@@ -0,0 +1,37 @@
+package lfu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLfuCache(t *testing.T) {
+	c := NewLfuCache(MaxEntries(2))
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Set("c", 3, 0)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected:1,got:%v,%v", v, ok)
+	}
+	c.Delete("a")
+	c.Set("d", 4, 0)
+	if items := c.Stats().Items; items != 2 {
+		t.Errorf("expected:2,got:%d", items)
+	}
+}
+
+func TestLfuCacheCleanup(t *testing.T) {
+	c := New[string, int](CleanupInterval(time.Millisecond * 20))
+	c.Set("a", 1, time.Millisecond*10)
+	c.Set("b", 2, time.Minute)
+	time.Sleep(time.Millisecond * 100)
+	if n := c.Len(); n != 1 {
+		t.Errorf("expected:1,got:%d", n)
+	}
+}
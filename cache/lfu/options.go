@@ -0,0 +1,42 @@
+package lfu
+
+import (
+	"time"
+)
+
+type Options struct {
+	MaxEntries int
+	// CleanupInterval is the interval of removing expired entries in
+	// background, zero means expired entries are only removed on Get
+	CleanupInterval time.Duration
+}
+
+type Option func(*Options)
+
+func newOptions(opts ...Option) Options {
+	option := Options{
+		MaxEntries: 1000,
+	}
+
+	for _, o := range opts {
+		o(&option)
+	}
+
+	if option.MaxEntries < 1 {
+		option.MaxEntries = 1
+	}
+
+	return option
+}
+
+func MaxEntries(maxEntries int) Option {
+	return func(o *Options) {
+		o.MaxEntries = maxEntries
+	}
+}
+
+func CleanupInterval(cleanupInterval time.Duration) Option {
+	return func(o *Options) {
+		o.CleanupInterval = cleanupInterval
+	}
+}
@@ -0,0 +1,257 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoLoader is returned by GetOrLoad when no loader is set
+var ErrNoLoader = errors.New("cache: loader not set")
+
+// LoaderFunc load value of key when it is missing in cache,
+// the returned ttl is used to cache the value
+type LoaderFunc func(ctx context.Context, key string) (interface{}, time.Duration, error)
+
+// maxLoaderState bound the number of keys whose loader state is kept,
+// dropping state only cost an extra load or a missed refresh
+const maxLoaderState = 10000
+
+// negative remember loader error of key until expireAt
+type negative struct {
+	err      error
+	expireAt int64
+}
+
+// soft is the refresh time of value loaded when SoftTTL is set, the
+// value is stale and refreshed in background after refreshAt
+type soft struct {
+	refreshAt int64
+	// expireAt is the hard expiration, zero means never
+	expireAt int64
+}
+
+// loaderState keep cached loader errors and soft expirations out of
+// BaseCache, so BaseCache only store plain values, which survive codecs
+// and atomic operations. it is nil when neither NegativeTTL nor SoftTTL
+// is used, so plain Get and Set never touch it
+type loaderState struct {
+	mu         sync.RWMutex
+	negatives  map[string]negative
+	softs      map[string]soft
+	refreshing map[string]struct{}
+}
+
+func newLoaderState(option Options) *loaderState {
+	if option.Loader == nil || (option.NegativeTTL <= 0 && option.SoftTTL <= 0) {
+		return nil
+	}
+	return &loaderState{
+		negatives:  make(map[string]negative),
+		softs:      make(map[string]soft),
+		refreshing: make(map[string]struct{}),
+	}
+}
+
+// negative return cached loader error of key, expired errors are
+// removed by forget or when negatives is full
+func (s *loaderState) negative(key string, now int64) (error, bool) {
+	s.mu.RLock()
+	n, ok := s.negatives[key]
+	s.mu.RUnlock()
+	if !ok || now > n.expireAt {
+		return nil, false
+	}
+	return n.err, true
+}
+
+// stale report whether value of key should be refreshed
+func (s *loaderState) stale(key string, now int64) bool {
+	s.mu.RLock()
+	sv, ok := s.softs[key]
+	s.mu.RUnlock()
+	return ok && now > sv.refreshAt
+}
+
+func (s *loaderState) setNegative(key string, n negative) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.negatives) >= maxLoaderState {
+		now := time.Now().UnixNano()
+		for k, v := range s.negatives {
+			if now > v.expireAt {
+				delete(s.negatives, k)
+			}
+		}
+		for k := range s.negatives {
+			if len(s.negatives) < maxLoaderState {
+				break
+			}
+			delete(s.negatives, k)
+		}
+	}
+	s.negatives[key] = n
+}
+
+// setLoaded clear negative of key, and record its soft expiration
+func (s *loaderState) setLoaded(key string, sv soft, isSoft bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.negatives, key)
+	if !isSoft {
+		delete(s.softs, key)
+		return
+	}
+	if len(s.softs) >= maxLoaderState {
+		now := time.Now().UnixNano()
+		for k, v := range s.softs {
+			if v.expireAt != 0 && now > v.expireAt {
+				delete(s.softs, k)
+			}
+		}
+		for k := range s.softs {
+			if len(s.softs) < maxLoaderState {
+				break
+			}
+			delete(s.softs, k)
+		}
+	}
+	s.softs[key] = sv
+}
+
+// postpone move refresh time of key to refreshAt, so a failing loader
+// is not called by every Get
+func (s *loaderState) postpone(key string, refreshAt int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sv, ok := s.softs[key]; ok {
+		sv.refreshAt = refreshAt
+		s.softs[key] = sv
+	}
+}
+
+// forget drop state of key, it is called when key is written by caller
+func (s *loaderState) forget(key string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	delete(s.negatives, key)
+	delete(s.softs, key)
+	s.mu.Unlock()
+}
+
+// forgetPrefix drop state of keys start with prefix
+func (s *loaderState) forgetPrefix(prefix string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.negatives {
+		if strings.HasPrefix(k, prefix) {
+			delete(s.negatives, k)
+		}
+	}
+	for k := range s.softs {
+		if strings.HasPrefix(k, prefix) {
+			delete(s.softs, k)
+		}
+	}
+}
+
+// GetOrLoad return cached value of key, or load it by loader.
+// concurrent loads of the same key are deduplicated, callers waiting
+// for the same key share the result of the first caller, including
+// the error caused by its ctx
+func (dc defaultCache) GetOrLoad(ctx context.Context, key string) (interface{}, error) {
+	if v, ok, err := dc.lookup(key); ok {
+		return v, err
+	}
+	if dc.option.Loader == nil {
+		return nil, ErrNoLoader
+	}
+
+	return dc.group.Do(key, func() (interface{}, error) {
+		return dc.load(ctx, key, false)
+	})
+}
+
+// lookup return value of key, err is cached loader error.
+// stale value is returned, and refreshed in background
+func (dc defaultCache) lookup(key string) (interface{}, bool, error) {
+	if dc.state == nil {
+		v, ok := dc.BaseCache.Get(key)
+		return v, ok, nil
+	}
+	now := time.Now().UnixNano()
+	if dc.option.NegativeTTL > 0 {
+		if err, ok := dc.state.negative(key, now); ok {
+			return nil, true, err
+		}
+	}
+	v, ok := dc.BaseCache.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	if dc.option.SoftTTL > 0 && dc.state.stale(key, now) {
+		dc.refresh(key)
+	}
+	return v, true, nil
+}
+
+// load call loader and cache its result. when refresh fails, the stale
+// value is kept until it expires, and next refresh is postponed
+func (dc defaultCache) load(ctx context.Context, key string, refresh bool) (interface{}, error) {
+	v, ttl, err := dc.option.Loader(ctx, key)
+	now := time.Now()
+	if err != nil {
+		if refresh {
+			backoff := dc.option.SoftTTL
+			if dc.option.NegativeTTL > 0 {
+				backoff = dc.option.NegativeTTL
+			}
+			dc.state.postpone(key, now.Add(backoff).UnixNano())
+		} else if dc.option.NegativeTTL > 0 {
+			dc.state.setNegative(key, negative{
+				err:      err,
+				expireAt: now.Add(dc.option.NegativeTTL).UnixNano(),
+			})
+		}
+		return nil, err
+	}
+	dc.BaseCache.Set(key, v, ttl)
+	sv := soft{refreshAt: now.Add(dc.option.SoftTTL).UnixNano()}
+	if ttl > 0 {
+		sv.expireAt = now.Add(ttl).UnixNano()
+	}
+	dc.state.setLoaded(key, sv, dc.option.SoftTTL > 0)
+	return v, nil
+}
+
+// refresh load key in background, only once at a time
+func (dc defaultCache) refresh(key string) {
+	dc.state.mu.Lock()
+	if _, ok := dc.state.refreshing[key]; ok {
+		dc.state.mu.Unlock()
+		return
+	}
+	dc.state.refreshing[key] = struct{}{}
+	dc.state.mu.Unlock()
+
+	go func() {
+		defer func() {
+			dc.state.mu.Lock()
+			delete(dc.state.refreshing, key)
+			dc.state.mu.Unlock()
+		}()
+		dc.group.Do(key, func() (interface{}, error) {
+			return dc.load(context.Background(), key, true)
+		})
+	}()
+}
@@ -1,52 +1,242 @@
 package lru
 
 import (
+	"runtime"
 	"sync"
 	"time"
 
 	"github.com/golang/groupcache/lru"
+	"github.com/haormj/util/cache/evict"
+	"github.com/haormj/util/cache/internal/entry"
+	"github.com/haormj/util/cache/internal/janitor"
+	"github.com/haormj/util/cache/stats"
 )
 
-// LruCache implements Cache by using memory
+// LruCache implements cache.BaseCache by using memory
 type LruCache struct {
+	*Cache[string, interface{}]
+}
+
+func NewLruCache(opts ...Option) *LruCache {
+	lruCache := &LruCache{
+		Cache: New[string, interface{}](opts...),
+	}
+
+	return lruCache
+}
+
+// Cache is a type-safe lru cache, implements cache.TypedCache.
+// entries are evicted by MaxEntries, and expired by their own ttl
+type Cache[K comparable, V any] struct {
+	*cache[K, V]
+	// janitor only hold inner cache, so Cache can be garbage collected
+	// and the finalizer stop the janitor, same as go-cache
+}
+
+type cache[K comparable, V any] struct {
 	rw     sync.RWMutex
 	option Options
-	cache  *lru.Cache
+	lru    *lru.Cache
+	// keys hold expiration of every key, groupcache lru is not iterable
+	keys    map[K]int64
+	cost    int64
+	janitor *janitor.Janitor
+	stats   stats.Counter
+	// reason of the lru operation in progress, guarded by rw
+	reason evict.Reason
+	// evictions are reported after rw is released
+	evictions entry.Evictions[K, V]
 }
 
-func NewLruCache(opts ...Option) *LruCache {
+func New[K comparable, V any](opts ...Option) *Cache[K, V] {
 	option := newOptions(opts...)
 
-	cache := lru.New(option.MaxEntries)
-
-	lruCache := &LruCache{
+	c := &cache[K, V]{
 		option: option,
-		cache:  cache,
+		lru:    lru.New(option.MaxEntries),
+		keys:   make(map[K]int64),
+	}
+	c.lru.OnEvicted = func(key lru.Key, value interface{}) {
+		i := value.(entry.Item[V])
+		delete(c.keys, key.(K))
+		c.cost -= i.Cost
+		switch c.reason {
+		case evict.Capacity:
+			c.stats.Evict()
+		case evict.Expired:
+			c.stats.Expire()
+		}
+		c.evictions.Add(key.(K), i.Value, c.reason)
 	}
 
-	return lruCache
+	C := &Cache[K, V]{c}
+	if option.CleanupInterval > 0 {
+		c.janitor = janitor.Run(option.CleanupInterval, c.DeleteExpired)
+		runtime.SetFinalizer(C, stopJanitor[K, V])
+	}
+
+	return C
 }
 
-func (mc *LruCache) Set(key string, value interface{}, ttl time.Duration) {
-	mc.rw.Lock()
-	mc.cache.Add(key, value)
-	mc.rw.Unlock()
+func stopJanitor[K comparable, V any](c *Cache[K, V]) {
+	c.janitor.Stop()
+}
+
+// Set add value to cache, ttl <= 0 means never expire.
+// cost of value is computed by Sizer
+func (c *cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.SetWithCost(key, value, c.sizeOf(value), ttl)
+}
+
+// SetWithCost add value with its cost to cache, least recently used
+// entries are evicted until total cost is under MaxCost, value whose
+// cost exceeds MaxCost is not cached
+func (c *cache[K, V]) SetWithCost(key K, value V, cost int64, ttl time.Duration) {
+	c.stats.Set()
+	c.rw.Lock()
+	c.set(key, value, cost, entry.Expiration(ttl))
+	c.unlock()
+}
+
+// set is Set with rw held
+func (c *cache[K, V]) set(key K, value V, cost int64, expiration int64) {
+	c.reason = evict.Capacity
+	if c.option.MaxCost > 0 && cost > int64(c.option.MaxCost) {
+		c.lru.Remove(key)
+		return
+	}
+	// groupcache lru replace value without OnEvicted
+	if old, ok := c.lru.Get(key); ok {
+		c.cost -= old.(entry.Item[V]).Cost
+	}
+	c.lru.Add(key, entry.Item[V]{Value: value, Expiration: expiration, Cost: cost})
+	c.cost += cost
+	c.keys[key] = expiration
+	if c.option.MaxCost > 0 {
+		for c.cost > int64(c.option.MaxCost) && c.lru.Len() > 0 {
+			c.lru.RemoveOldest()
+		}
+	}
 }
 
-func (mc *LruCache) Delete(key string) {
-	mc.rw.Lock()
-	mc.cache.Remove(key)
-	mc.rw.Unlock()
+// Cost return total cost of entries
+func (c *cache[K, V]) Cost() int64 {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	return c.cost
 }
 
-func (mc *LruCache) Get(key string) (interface{}, bool) {
-	mc.rw.RLock()
-	defer mc.rw.RUnlock()
-	return mc.cache.Get(key)
+func (c *cache[K, V]) Delete(key K) {
+	c.stats.Delete()
+	c.rw.Lock()
+	c.reason = evict.Deleted
+	c.lru.Remove(key)
+	c.unlock()
+}
+
+// Get lookup value from cache, expired entry is removed.
+// groupcache lru move entry to front on Get, so write lock is needed
+func (c *cache[K, V]) Get(key K) (V, bool) {
+	c.rw.Lock()
+	defer c.unlock()
+	i, ok := c.lookup(key)
+	if !ok {
+		c.stats.Miss()
+		return i.Value, false
+	}
+	c.stats.Hit()
+	return i.Value, true
+}
+
+// lookup is Get with rw held, without counting hit or miss
+func (c *cache[K, V]) lookup(key K) (entry.Item[V], bool) {
+	v, ok := c.lru.Get(key)
+	if !ok {
+		return entry.Item[V]{}, false
+	}
+	i := v.(entry.Item[V])
+	if i.Expired(time.Now().UnixNano()) {
+		c.reason = evict.Expired
+		c.lru.Remove(key)
+		return entry.Item[V]{}, false
+	}
+	return i, true
+}
+
+func (c *cache[K, V]) Clear() {
+	c.rw.Lock()
+	c.reason = evict.Cleared
+	c.lru.Clear()
+	c.keys = make(map[K]int64)
+	c.cost = 0
+	c.unlock()
+}
+
+// DeleteExpired remove all expired entries
+func (c *cache[K, V]) DeleteExpired() {
+	now := time.Now().UnixNano()
+	c.rw.Lock()
+	c.reason = evict.Expired
+	for key, expiration := range c.keys {
+		if expiration > 0 && now > expiration {
+			c.lru.Remove(key)
+		}
+	}
+	c.unlock()
+}
+
+// Keys return unexpired keys, in no particular order
+func (c *cache[K, V]) Keys() []K {
+	now := time.Now().UnixNano()
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	keys := make([]K, 0, len(c.keys))
+	for key, expiration := range c.keys {
+		if expiration == 0 || now <= expiration {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len return number of entries, it may include expired entries which
+// are not removed yet
+func (c *cache[K, V]) Len() int {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	return c.lru.Len()
+}
+
+// OnEvict add function called after entry is removed from cache,
+// functions added before are still called
+func (c *cache[K, V]) OnEvict(f evict.Func[K, V]) {
+	c.rw.Lock()
+	c.evictions.OnEvict(f)
+	c.rw.Unlock()
+}
+
+// Stats return counters and current item count
+func (c *cache[K, V]) Stats() stats.Stats {
+	c.rw.RLock()
+	items := c.lru.Len()
+	c.rw.RUnlock()
+	return c.stats.Snapshot(int64(items))
+}
+
+// ResetStats set all counters to zero
+func (c *cache[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+func (c *cache[K, V]) sizeOf(value V) int64 {
+	if c.option.Sizer == nil {
+		return 0
+	}
+	return c.option.Sizer(value)
 }
 
-func (mc *LruCache) Clear() {
-	mc.rw.Lock()
-	mc.cache.Clear()
-	mc.rw.Unlock()
+// unlock release rw, then report evicted entries, so onEvict is able
+// to call cache again
+func (c *cache[K, V]) unlock() {
+	c.evictions.Unlock(c.rw.Unlock)
 }
@@ -0,0 +1,72 @@
+package lru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haormj/util/humanize"
+)
+
+func TestLruCacheTTL(t *testing.T) {
+	c := NewLruCache(MaxEntries(2))
+	c.Set("a", 1, time.Millisecond*50)
+	c.Set("b", 2, 0)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected:1,got:%v,%v", v, ok)
+	}
+	time.Sleep(time.Millisecond * 100)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a expired")
+	}
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Errorf("expected:2,got:%v,%v", v, ok)
+	}
+	c.Set("c", 3, 0)
+	c.Set("d", 4, 0)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b evicted")
+	}
+}
+
+func TestLruCacheCleanup(t *testing.T) {
+	c := New[string, int](CleanupInterval(time.Millisecond * 20))
+	c.Set("a", 1, time.Millisecond*10)
+	c.Set("b", 2, time.Minute)
+	time.Sleep(time.Millisecond * 100)
+	c.rw.Lock()
+	n := c.lru.Len()
+	c.rw.Unlock()
+	if n != 1 {
+		t.Errorf("expected:1,got:%d", n)
+	}
+}
+
+func TestLruCacheCost(t *testing.T) {
+	c := New[string, []byte](
+		MaxEntries(0),
+		MaxCost(humanize.KiByte),
+		Sizer(func(value interface{}) int64 {
+			return int64(len(value.([]byte)))
+		}),
+	)
+	c.Set("a", make([]byte, 512), 0)
+	c.Set("b", make([]byte, 256), 0)
+	c.Get("a")
+	c.Set("c", make([]byte, 512), 0)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a cached")
+	}
+	if cost := c.Cost(); cost != 1024 {
+		t.Errorf("expected:1024,got:%d", cost)
+	}
+	c.SetWithCost("d", nil, 2048, 0)
+	if _, ok := c.Get("d"); ok {
+		t.Error("expected d exceed MaxCost")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a cached")
+	}
+}
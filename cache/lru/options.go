@@ -1,7 +1,22 @@
 package lru
 
+import (
+	"time"
+
+	"github.com/haormj/util/humanize"
+)
+
 type Options struct {
+	// MaxEntries is the maximum number of entries, zero means no limit
 	MaxEntries int
+	// MaxCost is the budget of total cost, least recently used entries
+	// are evicted until total cost is under it, zero means no limit
+	MaxCost humanize.Bytes
+	// Sizer compute cost of value on Set, cost is zero without Sizer
+	Sizer func(value interface{}) int64
+	// CleanupInterval is the interval of removing expired entries in
+	// background, zero means expired entries are only removed on Get
+	CleanupInterval time.Duration
 }
 
 type Option func(*Options)
@@ -23,3 +38,21 @@ func MaxEntries(maxEntries int) Option {
 		o.MaxEntries = maxEntries
 	}
 }
+
+func CleanupInterval(cleanupInterval time.Duration) Option {
+	return func(o *Options) {
+		o.CleanupInterval = cleanupInterval
+	}
+}
+
+func MaxCost(maxCost humanize.Bytes) Option {
+	return func(o *Options) {
+		o.MaxCost = maxCost
+	}
+}
+
+func Sizer(sizer func(value interface{}) int64) Option {
+	return func(o *Options) {
+		o.Sizer = sizer
+	}
+}
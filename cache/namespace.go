@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/haormj/util/cache/stats"
+)
+
+// namespaceSeparator join namespace and key
+const namespaceSeparator = ":"
+
+// namespaceEscaper escape separator in namespace, so the first unescaped
+// separator always ends namespace, and namespace "a" with key "b:c" never
+// collide with namespace "a:b" with key "c"
+var namespaceEscaper = strings.NewReplacer(`\`, `\\`, namespaceSeparator, `\`+namespaceSeparator)
+
+// namespacedCache is a view of BaseCache whose keys are prefixed by
+// namespace, its counters only count operations of the view
+type namespacedCache struct {
+	baseCache BaseCache
+	prefix    string
+	stats     *stats.Counter
+}
+
+func newNamespacedCache(baseCache BaseCache, namespace string) BaseCache {
+	nc := namespacedCache{
+		baseCache: baseCache,
+		prefix:    namespaceEscaper.Replace(namespace) + namespaceSeparator,
+		stats:     &stats.Counter{},
+	}
+	if ac, ok := baseCache.(atomicCache); ok {
+		return atomicNamespacedCache{namespacedCache: nc, atomic: ac}
+	}
+	return nc
+}
+
+func (nc namespacedCache) Set(key string, value interface{}, ttl time.Duration) {
+	nc.stats.Set()
+	nc.baseCache.Set(nc.prefix+key, value, ttl)
+}
+
+func (nc namespacedCache) Get(key string) (interface{}, bool) {
+	v, ok := nc.baseCache.Get(nc.prefix + key)
+	if ok {
+		nc.stats.Hit()
+	} else {
+		nc.stats.Miss()
+	}
+	return v, ok
+}
+
+func (nc namespacedCache) Delete(key string) {
+	nc.stats.Delete()
+	nc.baseCache.Delete(nc.prefix + key)
+}
+
+// Clear only delete keys of namespace
+func (nc namespacedCache) Clear() {
+	nc.DeletePrefix("")
+}
+
+func (nc namespacedCache) Keys() []string {
+	return nc.KeysPrefix("")
+}
+
+// KeysPrefix return keys of namespace start with prefix, the prefix is
+// pushed down to BaseCache when it support prefix lookup
+func (nc namespacedCache) KeysPrefix(prefix string) []string {
+	var keys []string
+	if pc, ok := nc.baseCache.(prefixCache); ok {
+		keys = pc.KeysPrefix(nc.prefix + prefix)
+	} else {
+		keys = nc.baseCache.Keys()
+	}
+	var result []string
+	for _, key := range keys {
+		if strings.HasPrefix(key, nc.prefix+prefix) {
+			result = append(result, strings.TrimPrefix(key, nc.prefix))
+		}
+	}
+	return result
+}
+
+// DeletePrefix delete keys of namespace start with prefix
+func (nc namespacedCache) DeletePrefix(prefix string) int {
+	if pc, ok := nc.baseCache.(prefixCache); ok {
+		return pc.DeletePrefix(nc.prefix + prefix)
+	}
+	return deletePrefix(nc.baseCache, nc.prefix+prefix)
+}
+
+func (nc namespacedCache) Len() int {
+	return len(nc.Keys())
+}
+
+// Stats return counters of namespace, evictions and expirations are
+// not attributed to namespace
+func (nc namespacedCache) Stats() stats.Stats {
+	return nc.stats.Snapshot(int64(nc.Len()))
+}
+
+func (nc namespacedCache) ResetStats() {
+	nc.stats.Reset()
+}
+
+// atomicNamespacedCache is namespacedCache over BaseCache which
+// support atomic operations
+type atomicNamespacedCache struct {
+	namespacedCache
+	atomic atomicCache
+}
+
+func (nc atomicNamespacedCache) Add(key string, value interface{}, ttl time.Duration) bool {
+	nc.stats.Set()
+	return nc.atomic.Add(nc.prefix+key, value, ttl)
+}
+
+func (nc atomicNamespacedCache) Replace(key string, value interface{}, ttl time.Duration) bool {
+	nc.stats.Set()
+	return nc.atomic.Replace(nc.prefix+key, value, ttl)
+}
+
+func (nc atomicNamespacedCache) CompareAndSwap(key string, old, new interface{}, ttl time.Duration) bool {
+	nc.stats.Set()
+	return nc.atomic.CompareAndSwap(nc.prefix+key, old, new, ttl)
+}
+
+func (nc atomicNamespacedCache) Incr(key string, delta int64, ttl time.Duration) (int64, error) {
+	nc.stats.Set()
+	return nc.atomic.Incr(nc.prefix+key, delta, ttl)
+}
+
+func (nc atomicNamespacedCache) Decr(key string, delta int64, ttl time.Duration) (int64, error) {
+	return nc.Incr(key, -delta, ttl)
+}
@@ -1,11 +1,30 @@
 package cache
 
 import (
+	"time"
+
 	"github.com/haormj/util/cache/ttl"
 )
 
 type Options struct {
 	BaseCache BaseCache
+	// Loader is used by GetOrLoad to load missing value
+	Loader LoaderFunc
+	// NegativeTTL is how long loader error is cached, zero means
+	// error is not cached
+	NegativeTTL time.Duration
+	// SoftTTL is how long value loaded by Loader is fresh, stale value
+	// is still returned and refreshed in background, until the ttl
+	// returned by Loader expires. zero means never refresh
+	SoftTTL time.Duration
+	// OnEvict is called after entry is removed from BaseCache, it
+	// only works when BaseCache is able to report removed entries
+	OnEvict EvictFunc
+	// Namespace make Cache a view of BaseCache whose keys are prefixed
+	// by "Namespace:", so components are able to share one BaseCache.
+	// ":" and `\` in Namespace are escaped by `\`.
+	// OnEvict does not work with Namespace
+	Namespace string
 }
 
 type Option func(*Options)
@@ -21,3 +40,46 @@ func newOptions(opts ...Option) Options {
 
 	return option
 }
+
+func WithBaseCache(baseCache BaseCache) Option {
+	return func(o *Options) {
+		o.BaseCache = baseCache
+	}
+}
+
+func Loader(loader LoaderFunc) Option {
+	return func(o *Options) {
+		o.Loader = loader
+	}
+}
+
+func NegativeTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.NegativeTTL = ttl
+	}
+}
+
+func SoftTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.SoftTTL = ttl
+	}
+}
+
+// OnEvict set function called after entry is removed from BaseCache.
+// it is ignored when WithNamespace is used, because evictions of the
+// shared BaseCache are not attributed to namespace
+func OnEvict(f EvictFunc) Option {
+	return func(o *Options) {
+		o.OnEvict = f
+	}
+}
+
+// WithNamespace make Cache a view of BaseCache, its Clear only delete
+// keys of namespace and its Stats only count operations of the view.
+// separator in namespace is escaped, so namespaces never collide.
+// OnEvict does not work with it
+func WithNamespace(namespace string) Option {
+	return func(o *Options) {
+		o.Namespace = namespace
+	}
+}
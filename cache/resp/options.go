@@ -0,0 +1,94 @@
+package resp
+
+import (
+	"time"
+
+	"github.com/haormj/util/cache/codec"
+)
+
+type Options struct {
+	// Addr is host:port of server
+	Addr string
+	// Password is sent by AUTH when it is not empty
+	Password string
+	// DB is selected by SELECT when it is not zero
+	DB int
+	// PoolSize is the maximum number of connections
+	PoolSize     int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// Codec serialize values
+	Codec codec.Codec
+}
+
+type Option func(*Options)
+
+func newOptions(opts ...Option) Options {
+	option := Options{
+		Addr:         "127.0.0.1:6379",
+		PoolSize:     10,
+		DialTimeout:  time.Second * 5,
+		ReadTimeout:  time.Second * 3,
+		WriteTimeout: time.Second * 3,
+		Codec:        codec.Gob,
+	}
+
+	for _, o := range opts {
+		o(&option)
+	}
+
+	if option.PoolSize < 1 {
+		option.PoolSize = 1
+	}
+
+	return option
+}
+
+func Addr(addr string) Option {
+	return func(o *Options) {
+		o.Addr = addr
+	}
+}
+
+func Password(password string) Option {
+	return func(o *Options) {
+		o.Password = password
+	}
+}
+
+func DB(db int) Option {
+	return func(o *Options) {
+		o.DB = db
+	}
+}
+
+func PoolSize(poolSize int) Option {
+	return func(o *Options) {
+		o.PoolSize = poolSize
+	}
+}
+
+func DialTimeout(dialTimeout time.Duration) Option {
+	return func(o *Options) {
+		o.DialTimeout = dialTimeout
+	}
+}
+
+func ReadTimeout(readTimeout time.Duration) Option {
+	return func(o *Options) {
+		o.ReadTimeout = readTimeout
+	}
+}
+
+func WriteTimeout(writeTimeout time.Duration) Option {
+	return func(o *Options) {
+		o.WriteTimeout = writeTimeout
+	}
+}
+
+func Codec(c codec.Codec) Option {
+	return func(o *Options) {
+		o.Codec = c
+	}
+}
@@ -0,0 +1,163 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+// ErrPoolClosed is returned when pool is closed
+var ErrPoolClosed = errors.New("resp: pool closed")
+
+type conn struct {
+	netConn net.Conn
+	br      *bufio.Reader
+	bw      *bufio.Writer
+}
+
+// pool keep at most PoolSize connections, idle connections are reused
+type pool struct {
+	option Options
+	sem    chan struct{}
+	idle   chan *conn
+	closed chan struct{}
+}
+
+func newPool(option Options) *pool {
+	return &pool{
+		option: option,
+		sem:    make(chan struct{}, option.PoolSize),
+		idle:   make(chan *conn, option.PoolSize),
+		closed: make(chan struct{}),
+	}
+}
+
+// do send command by a pooled connection and read its reply,
+// connection is closed when network error happen
+func (p *pool) do(args ...[]byte) (interface{}, error) {
+	cn, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := cn.do(p.option, args...)
+	p.put(cn, err)
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := reply.(Error); ok {
+		return nil, e
+	}
+	return reply, nil
+}
+
+func (p *pool) get() (*conn, error) {
+	timer := time.NewTimer(p.option.DialTimeout)
+	defer timer.Stop()
+	select {
+	case p.sem <- struct{}{}:
+	case <-timer.C:
+		return nil, errors.New("resp: wait for connection timeout")
+	case <-p.closed:
+		return nil, ErrPoolClosed
+	}
+	select {
+	case cn := <-p.idle:
+		return cn, nil
+	default:
+	}
+	cn, err := p.dial()
+	if err != nil {
+		<-p.sem
+		return nil, err
+	}
+	return cn, nil
+}
+
+// put return cn to idle, err is network or protocol error of cn,
+// error reply of server is not included
+func (p *pool) put(cn *conn, err error) {
+	defer func() { <-p.sem }()
+	if err != nil {
+		cn.netConn.Close()
+		return
+	}
+	select {
+	case <-p.closed:
+		cn.netConn.Close()
+		return
+	default:
+	}
+	select {
+	case p.idle <- cn:
+	default:
+		cn.netConn.Close()
+	}
+}
+
+func (p *pool) dial() (*conn, error) {
+	netConn, err := net.DialTimeout("tcp", p.option.Addr, p.option.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{
+		netConn: netConn,
+		br:      bufio.NewReader(netConn),
+		bw:      bufio.NewWriter(netConn),
+	}
+	if p.option.Password != "" {
+		if err := cn.expectOK(p.option, []byte("AUTH"), []byte(p.option.Password)); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if p.option.DB != 0 {
+		if err := cn.expectOK(p.option, []byte("SELECT"), []byte(strconv.Itoa(p.option.DB))); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+func (p *pool) close() {
+	select {
+	case <-p.closed:
+		return
+	default:
+		close(p.closed)
+	}
+	for {
+		select {
+		case cn := <-p.idle:
+			cn.netConn.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (cn *conn) do(option Options, args ...[]byte) (interface{}, error) {
+	if option.WriteTimeout > 0 {
+		cn.netConn.SetWriteDeadline(time.Now().Add(option.WriteTimeout))
+	}
+	if err := writeCommand(cn.bw, args...); err != nil {
+		return nil, err
+	}
+	if option.ReadTimeout > 0 {
+		cn.netConn.SetReadDeadline(time.Now().Add(option.ReadTimeout))
+	}
+	return readReply(cn.br)
+}
+
+func (cn *conn) expectOK(option Options, args ...[]byte) error {
+	reply, err := cn.do(option, args...)
+	if err != nil {
+		return err
+	}
+	if e, ok := reply.(Error); ok {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Error is error reply of server
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+var errProtocol = errors.New("resp: protocol error")
+
+const (
+	// maxBulkLen is the maximum length of bulk string, same as redis
+	maxBulkLen = 512 * 1024 * 1024
+	// maxArrayLen is the maximum number of array elements
+	maxArrayLen = 1024 * 1024 * 1024
+	// maxArrayPrealloc limit memory allocated before elements are read
+	maxArrayPrealloc = 1024
+)
+
+// writeCommand write args as array of bulk strings
+func writeCommand(w *bufio.Writer, args ...[]byte) error {
+	w.WriteString("*")
+	w.WriteString(strconv.Itoa(len(args)))
+	w.WriteString("\r\n")
+	for _, arg := range args {
+		writeBulk(w, arg)
+	}
+	return w.Flush()
+}
+
+func writeBulk(w *bufio.Writer, b []byte) {
+	if b == nil {
+		w.WriteString("$-1\r\n")
+		return
+	}
+	w.WriteString("$")
+	w.WriteString(strconv.Itoa(len(b)))
+	w.WriteString("\r\n")
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+// readReply read one reply, it is string for simple string, Error,
+// int64, []byte for bulk string (nil when null) or []interface{}
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errProtocol
+	}
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		n, err := readLen(line, maxBulkLen)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return []byte(nil), nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		if b[n] != '\r' || b[n+1] != '\n' {
+			return nil, errProtocol
+		}
+		return b[:n], nil
+	case '*':
+		n, err := readLen(line, maxArrayLen)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return []interface{}(nil), nil
+		}
+		prealloc := n
+		if prealloc > maxArrayPrealloc {
+			prealloc = maxArrayPrealloc
+		}
+		replies := make([]interface{}, 0, prealloc)
+		for i := 0; i < n; i++ {
+			reply, err := readReply(r)
+			if err != nil {
+				return nil, err
+			}
+			replies = append(replies, reply)
+		}
+		return replies, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q", errProtocol, line[0])
+	}
+}
+
+// readLen parse length of bulk string or array, -1 means null
+func readLen(line []byte, max int) (int, error) {
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < -1 || n > max {
+		return 0, errProtocol
+	}
+	return n, nil
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, errProtocol
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,254 @@
+// Package resp implements cache backend which speak Redis RESP protocol,
+// so cache is shared by processes. errors are logged and Get treat
+// them as missing
+package resp
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/haormj/util/log"
+)
+
+// RespCache implements cache.BaseCache by using remote server
+type RespCache struct {
+	*Cache[string, interface{}]
+}
+
+func NewRespCache(opts ...Option) *RespCache {
+	respCache := &RespCache{
+		Cache: New[string, interface{}](opts...),
+	}
+	return respCache
+}
+
+// Cache is a type-safe remote cache, implements cache.TypedCache.
+// values are serialized by Codec
+type Cache[K ~string, V any] struct {
+	option Options
+	pool   *pool
+}
+
+func New[K ~string, V any](opts ...Option) *Cache[K, V] {
+	option := newOptions(opts...)
+
+	c := &Cache[K, V]{
+		option: option,
+		pool:   newPool(option),
+	}
+	return c
+}
+
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	if err := c.SetE(key, value, ttl); err != nil {
+		log.Error("resp set ", key, " failed: ", err)
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	value, ok, err := c.GetE(key)
+	if err != nil {
+		log.Error("resp get ", key, " failed: ", err)
+	}
+	return value, ok
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	if err := c.DeleteE(key); err != nil {
+		log.Error("resp delete ", key, " failed: ", err)
+	}
+}
+
+func (c *Cache[K, V]) Clear() {
+	if err := c.ClearE(); err != nil {
+		log.Error("resp clear failed: ", err)
+	}
+}
+
+// Keys return all keys of the selected db, they are iterated by SCAN,
+// so server is not blocked
+func (c *Cache[K, V]) Keys() []K {
+	keys, err := c.KeysE()
+	if err != nil {
+		log.Error("resp keys failed: ", err)
+	}
+	return keys
+}
+
+// KeysPrefix return keys start with prefix, matched by server
+func (c *Cache[K, V]) KeysPrefix(prefix string) []K {
+	keys, err := c.KeysPrefixE(prefix)
+	if err != nil {
+		log.Error("resp keys prefix ", prefix, " failed: ", err)
+	}
+	return keys
+}
+
+// DeletePrefix delete keys start with prefix, and return the number of
+// deleted keys
+func (c *Cache[K, V]) DeletePrefix(prefix string) int {
+	n, err := c.DeletePrefixE(prefix)
+	if err != nil {
+		log.Error("resp delete prefix ", prefix, " failed: ", err)
+	}
+	return n
+}
+
+func (c *Cache[K, V]) Len() int {
+	n, err := c.LenE()
+	if err != nil {
+		log.Error("resp len failed: ", err)
+	}
+	return n
+}
+
+// SetE is Set which return error, ttl is sent in milliseconds by PX
+func (c *Cache[K, V]) SetE(key K, value V, ttl time.Duration) error {
+	data, err := c.option.Codec.Marshal(&value)
+	if err != nil {
+		return err
+	}
+	args := [][]byte{[]byte("SET"), []byte(key), data}
+	if ttl > 0 {
+		ms := ttl.Milliseconds()
+		if ms < 1 {
+			ms = 1
+		}
+		args = append(args, []byte("PX"), []byte(strconv.FormatInt(ms, 10)))
+	}
+	_, err = c.pool.do(args...)
+	return err
+}
+
+// GetE is Get which return error
+func (c *Cache[K, V]) GetE(key K) (V, bool, error) {
+	var value V
+	reply, err := c.pool.do([]byte("GET"), []byte(key))
+	if err != nil {
+		return value, false, err
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return value, false, errProtocol
+	}
+	if data == nil {
+		return value, false, nil
+	}
+	if err := c.option.Codec.Unmarshal(data, &value); err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// DeleteE is Delete which return error
+func (c *Cache[K, V]) DeleteE(key K) error {
+	_, err := c.pool.do([]byte("DEL"), []byte(key))
+	return err
+}
+
+// ClearE is Clear which return error, it flush the selected db
+func (c *Cache[K, V]) ClearE() error {
+	_, err := c.pool.do([]byte("FLUSHDB"))
+	return err
+}
+
+// KeysE is Keys which return error
+func (c *Cache[K, V]) KeysE() ([]K, error) {
+	return c.KeysPrefixE("")
+}
+
+// KeysPrefixE is KeysPrefix which return error
+func (c *Cache[K, V]) KeysPrefixE(prefix string) ([]K, error) {
+	var keys []K
+	err := c.scan(prefixPattern(prefix), func(batch [][]byte) error {
+		for _, key := range batch {
+			keys = append(keys, K(key))
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// DeletePrefixE is DeletePrefix which return error, keys are deleted
+// batch by batch while scanning
+func (c *Cache[K, V]) DeletePrefixE(prefix string) (int, error) {
+	var n int
+	err := c.scan(prefixPattern(prefix), func(batch [][]byte) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		args := append([][]byte{[]byte("DEL")}, batch...)
+		reply, err := c.pool.do(args...)
+		if err != nil {
+			return err
+		}
+		deleted, ok := reply.(int64)
+		if !ok {
+			return errProtocol
+		}
+		n += int(deleted)
+		return nil
+	})
+	return n, err
+}
+
+// scanCount is the COUNT hint of SCAN
+const scanCount = "1000"
+
+// scan iterate keys matching pattern by SCAN, f is called with each batch
+func (c *Cache[K, V]) scan(pattern string, f func([][]byte) error) error {
+	cursor := []byte("0")
+	for {
+		reply, err := c.pool.do([]byte("SCAN"), cursor,
+			[]byte("MATCH"), []byte(pattern), []byte("COUNT"), []byte(scanCount))
+		if err != nil {
+			return err
+		}
+		replies, ok := reply.([]interface{})
+		if !ok || len(replies) != 2 {
+			return errProtocol
+		}
+		next, ok := replies[0].([]byte)
+		if !ok {
+			return errProtocol
+		}
+		items, ok := replies[1].([]interface{})
+		if !ok {
+			return errProtocol
+		}
+		batch := make([][]byte, 0, len(items))
+		for _, item := range items {
+			key, ok := item.([]byte)
+			if !ok {
+				return errProtocol
+			}
+			batch = append(batch, key)
+		}
+		if err := f(batch); err != nil {
+			return err
+		}
+		if string(next) == "0" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// LenE is Len which return error
+func (c *Cache[K, V]) LenE() (int, error) {
+	reply, err := c.pool.do([]byte("DBSIZE"))
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, errProtocol
+	}
+	return int(n), nil
+}
+
+// Close close all idle connections, cache is not usable after Close
+func (c *Cache[K, V]) Close() error {
+	c.pool.close()
+	return nil
+}
@@ -0,0 +1,229 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haormj/util/cache"
+	"github.com/haormj/util/cache/codec"
+)
+
+func TestRespCache(t *testing.T) {
+	server, err := NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	c := NewRespCache(Addr(server.Addr()), PoolSize(2))
+	defer c.Close()
+
+	shared := cache.NewCache(cache.WithBaseCache(c))
+	shared.Set("hello", "world", time.Minute)
+	shared.Set("expired", 1, time.Millisecond)
+	time.Sleep(time.Millisecond * 10)
+	if v, ok := shared.GetString("hello"); !ok || v != "world" {
+		t.Errorf("expected:world,got:%v,%v", v, ok)
+	}
+	if _, ok := shared.Get("expired"); ok {
+		t.Error("expected expired")
+	}
+	if n := shared.Len(); n != 1 {
+		t.Errorf("expected:1,got:%d", n)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := shared.Get("hello"); !ok {
+				t.Error("expected hello")
+			}
+		}()
+	}
+	wg.Wait()
+
+	shared.Delete("hello")
+	if _, ok := shared.Get("hello"); ok {
+		t.Error("expected deleted")
+	}
+	shared.Set("a", 1, 0)
+	shared.Clear()
+	if keys := shared.Keys(); len(keys) != 0 {
+		t.Errorf("expected no keys,got:%v", keys)
+	}
+
+	typed := New[string, int](Addr(server.Addr()))
+	defer typed.Close()
+	if err := typed.SetE("n", 42, 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := typed.GetE("n"); !ok || err != nil || v != 42 {
+		t.Errorf("expected:42,got:%v,%v,%v", v, ok, err)
+	}
+}
+
+func TestRespCacheTimeout(t *testing.T) {
+	c := New[string, int](Addr("127.0.0.1:1"), DialTimeout(time.Millisecond*100))
+	defer c.Close()
+	if _, _, err := c.GetE("a"); err == nil {
+		t.Error("expected dial error")
+	}
+}
+
+func TestRespCacheCodec(t *testing.T) {
+	server, err := NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	c := NewRespCache(Addr(server.Addr()), Codec(codec.JSON))
+	defer c.Close()
+	shared := cache.NewCache(cache.WithBaseCache(c))
+	shared.Set("user", map[string]interface{}{"name": "hao"}, 0)
+
+	raw := New[string, []byte](Addr(server.Addr()), Codec(codec.Raw))
+	defer raw.Close()
+	if v, ok := raw.Get("user"); !ok || string(v) != `{"name":"hao"}` {
+		t.Errorf("expected json,got:%s,%v", v, ok)
+	}
+	var user struct {
+		Name string `json:"name"`
+	}
+	if ok, err := cache.GetAs(shared, "user", &user); !ok || err != nil || user.Name != "hao" {
+		t.Errorf("expected:hao,got:%+v,%v,%v", user, ok, err)
+	}
+}
+
+func TestRespGetOrLoad(t *testing.T) {
+	server, err := NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	errNotFound := errors.New("not found")
+	for name, c := range map[string]codec.Codec{"gob": codec.Gob, "json": codec.JSON} {
+		var loads int32
+		backend := NewRespCache(Addr(server.Addr()), Codec(c))
+		shared := cache.NewCache(
+			cache.WithBaseCache(backend),
+			cache.WithNamespace(name),
+			cache.Loader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+				atomic.AddInt32(&loads, 1)
+				if key == "missing" {
+					return nil, 0, errNotFound
+				}
+				return "value of " + key, time.Minute, nil
+			}),
+			cache.NegativeTTL(time.Minute),
+			cache.SoftTTL(time.Minute),
+		)
+		for i := 0; i < 2; i++ {
+			if v, err := shared.GetOrLoad(context.Background(), "a"); err != nil || v != "value of a" {
+				t.Errorf("%s: expected:value of a,got:%v,%v", name, v, err)
+			}
+			if _, err := shared.GetOrLoad(context.Background(), "missing"); err != errNotFound {
+				t.Errorf("%s: expected:%v,got:%v", name, errNotFound, err)
+			}
+		}
+		if n := atomic.LoadInt32(&loads); n != 2 {
+			t.Errorf("%s: expected:2 loads,got:%d", name, n)
+		}
+		if v, ok := shared.GetString("a"); !ok || v != "value of a" {
+			t.Errorf("%s: expected:value of a,got:%v,%v", name, v, ok)
+		}
+		backend.Close()
+	}
+}
+
+func TestRespPrefix(t *testing.T) {
+	server, err := NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	c := NewRespCache(Addr(server.Addr()))
+	defer c.Close()
+	shared := cache.NewCache(cache.WithBaseCache(c))
+	for i := 0; i < 2500; i++ {
+		shared.Set("files/"+strconv.Itoa(i), i, time.Minute)
+	}
+	shared.Set("f*", 1, time.Minute)
+	shared.Set("other", 1, time.Minute)
+	if keys := c.KeysPrefix("files/"); len(keys) != 2500 {
+		t.Errorf("expected:2500,got:%d", len(keys))
+	}
+	if n := shared.DeletePrefix("f*"); n != 1 {
+		t.Errorf("expected glob in prefix matched literally,got:%d", n)
+	}
+	if n := shared.DeletePrefix("files/"); n != 2500 {
+		t.Errorf("expected:2500,got:%d", n)
+	}
+	if keys := shared.Keys(); len(keys) != 1 || keys[0] != "other" {
+		t.Errorf("expected:[other],got:%v", keys)
+	}
+
+	users := cache.NewCache(cache.WithBaseCache(c), cache.WithNamespace("users"))
+	users.Set("a/1", 1, time.Minute)
+	users.Set("b", 2, time.Minute)
+	if keys := users.Keys(); len(keys) != 2 {
+		t.Errorf("expected 2 keys,got:%v", keys)
+	}
+	if n := users.DeletePrefix("a/"); n != 1 {
+		t.Errorf("expected:1,got:%d", n)
+	}
+	users.Clear()
+	if n := users.Len(); n != 0 {
+		t.Errorf("expected:0,got:%d", n)
+	}
+	if _, ok := shared.Get("other"); !ok {
+		t.Error("expected other kept after namespace Clear")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern string
+		s       string
+		matched bool
+	}{
+		{"*", "a/b", true},
+		{"a*", "a/b/c", true},
+		{"a?c", "a/c", true},
+		{"a?c", "ac", false},
+		{"a[bc]d", "acd", true},
+		{"a[^bc]d", "acd", false},
+		{"a[a-c]d", "abd", true},
+		{`a\*`, "a*", true},
+		{`a\*`, "ab", false},
+		{"a[b", "a[b", true},
+		{"*b*", "aaa", false},
+	}
+	for _, c := range cases {
+		if matched := matchGlob(c.pattern, c.s); matched != c.matched {
+			t.Errorf("%q %q: expected:%v,got:%v", c.pattern, c.s, c.matched, matched)
+		}
+	}
+}
+
+func TestReadReplyLimit(t *testing.T) {
+	for _, s := range []string{"$-2\r\n", "*-2\r\n", "$536870913\r\n", "*2147483648\r\n", "$1\r\nab\r\n"} {
+		if _, err := readReply(bufio.NewReader(strings.NewReader(s))); err != errProtocol {
+			t.Errorf("%q: expected:%v,got:%v", s, errProtocol, err)
+		}
+	}
+	if v, err := readReply(bufio.NewReader(strings.NewReader("*-1\r\n"))); err != nil || v.([]interface{}) != nil {
+		t.Errorf("expected null array,got:%v,%v", v, err)
+	}
+}
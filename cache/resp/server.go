@@ -0,0 +1,313 @@
+package resp
+
+import (
+	"bufio"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a tiny in-process RESP server, it support PING, AUTH,
+// SELECT, GET, SET with EX/PX/NX/XX, DEL, FLUSHDB, KEYS, SCAN and DBSIZE.
+// it is meant for tests, all dbs share one keyspace
+type Server struct {
+	listener net.Listener
+	mu       sync.Mutex
+	items    map[string]serverItem
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+
+	// scans map SCAN cursor to the last key returned, keys are scanned
+	// in sorted order, so keys deleted during scan are not skipped
+	scans  map[int64]string
+	cursor int64
+}
+
+type serverItem struct {
+	value      []byte
+	expiration int64
+}
+
+func (i serverItem) expired(now int64) bool {
+	return i.expiration > 0 && now > i.expiration
+}
+
+// NewServer listen on addr, such as "127.0.0.1:0", and serve in background
+func NewServer(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: listener,
+		items:    make(map[string]serverItem),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr return the listening address
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stop listening and close all connections
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+		s.wg.Done()
+	}()
+	br := bufio.NewReader(conn)
+	bw := bufio.NewWriter(conn)
+	for {
+		reply, err := readReply(br)
+		if err != nil {
+			return
+		}
+		args, ok := reply.([]interface{})
+		if !ok || len(args) == 0 {
+			writeError(bw, "ERR invalid command")
+		} else {
+			s.exec(bw, args)
+		}
+		if err := bw.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(w *bufio.Writer, args []interface{}) {
+	argv := make([]string, len(args))
+	for i, arg := range args {
+		b, ok := arg.([]byte)
+		if !ok {
+			writeError(w, "ERR invalid argument")
+			return
+		}
+		argv[i] = string(b)
+	}
+
+	now := time.Now().UnixNano()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch strings.ToUpper(argv[0]) {
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "AUTH", "SELECT":
+		w.WriteString("+OK\r\n")
+	case "GET":
+		if len(argv) != 2 {
+			writeError(w, "ERR wrong number of arguments")
+			return
+		}
+		i, ok := s.items[argv[1]]
+		if !ok || i.expired(now) {
+			delete(s.items, argv[1])
+			writeBulk(w, nil)
+			return
+		}
+		writeBulk(w, i.value)
+	case "SET":
+		s.set(w, argv, now)
+	case "DEL":
+		var n int
+		for _, key := range argv[1:] {
+			if i, ok := s.items[key]; ok && !i.expired(now) {
+				n++
+			}
+			delete(s.items, key)
+		}
+		writeInt(w, n)
+	case "FLUSHDB":
+		s.items = make(map[string]serverItem)
+		w.WriteString("+OK\r\n")
+	case "KEYS":
+		if len(argv) != 2 {
+			writeError(w, "ERR wrong number of arguments")
+			return
+		}
+		var keys []string
+		for key, i := range s.items {
+			if matchGlob(argv[1], key) && !i.expired(now) {
+				keys = append(keys, key)
+			}
+		}
+		w.WriteString("*" + strconv.Itoa(len(keys)) + "\r\n")
+		for _, key := range keys {
+			writeBulk(w, []byte(key))
+		}
+	case "SCAN":
+		s.scan(w, argv, now)
+	case "DBSIZE":
+		var n int
+		for _, i := range s.items {
+			if !i.expired(now) {
+				n++
+			}
+		}
+		writeInt(w, n)
+	default:
+		writeError(w, "ERR unknown command '"+argv[0]+"'")
+	}
+}
+
+// set handle SET key value [EX seconds|PX milliseconds] [NX|XX]
+func (s *Server) set(w *bufio.Writer, argv []string, now int64) {
+	if len(argv) < 3 {
+		writeError(w, "ERR wrong number of arguments")
+		return
+	}
+	var (
+		expiration int64
+		nx, xx     bool
+	)
+	for i := 3; i < len(argv); i++ {
+		switch strings.ToUpper(argv[i]) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if i+1 >= len(argv) {
+				writeError(w, "ERR syntax error")
+				return
+			}
+			n, err := strconv.ParseInt(argv[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				writeError(w, "ERR invalid expire time")
+				return
+			}
+			unit := time.Millisecond
+			if strings.ToUpper(argv[i]) == "EX" {
+				unit = time.Second
+			}
+			expiration = now + n*int64(unit)
+			i++
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+	i, exist := s.items[argv[1]]
+	exist = exist && !i.expired(now)
+	if (nx && exist) || (xx && !exist) {
+		writeBulk(w, nil)
+		return
+	}
+	s.items[argv[1]] = serverItem{value: []byte(argv[2]), expiration: expiration}
+	w.WriteString("+OK\r\n")
+}
+
+// scan handle SCAN cursor [MATCH pattern] [COUNT count]
+func (s *Server) scan(w *bufio.Writer, argv []string, now int64) {
+	if len(argv) < 2 {
+		writeError(w, "ERR wrong number of arguments")
+		return
+	}
+	cursor, err := strconv.ParseInt(argv[1], 10, 64)
+	if err != nil {
+		writeError(w, "ERR invalid cursor")
+		return
+	}
+	match, count := "*", 10
+	for i := 2; i < len(argv); i += 2 {
+		if i+1 >= len(argv) {
+			writeError(w, "ERR syntax error")
+			return
+		}
+		switch strings.ToUpper(argv[i]) {
+		case "MATCH":
+			match = argv[i+1]
+		case "COUNT":
+			count, err = strconv.Atoi(argv[i+1])
+			if err != nil || count <= 0 {
+				writeError(w, "ERR syntax error")
+				return
+			}
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+	var last string
+	if cursor != 0 {
+		var ok bool
+		if last, ok = s.scans[cursor]; !ok {
+			writeError(w, "ERR invalid cursor")
+			return
+		}
+		delete(s.scans, cursor)
+	}
+
+	all := make([]string, 0, len(s.items))
+	for key := range s.items {
+		if cursor == 0 || key > last {
+			all = append(all, key)
+		}
+	}
+	sort.Strings(all)
+	next := int64(0)
+	if len(all) > count {
+		all = all[:count]
+		if s.scans == nil {
+			s.scans = make(map[int64]string)
+		}
+		s.cursor++
+		next = s.cursor
+		s.scans[next] = all[len(all)-1]
+	}
+	var keys []string
+	for _, key := range all {
+		if i := s.items[key]; matchGlob(match, key) && !i.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	w.WriteString("*2\r\n")
+	writeBulk(w, []byte(strconv.FormatInt(next, 10)))
+	w.WriteString("*" + strconv.Itoa(len(keys)) + "\r\n")
+	for _, key := range keys {
+		writeBulk(w, []byte(key))
+	}
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	w.WriteString("-" + msg + "\r\n")
+}
+
+func writeInt(w *bufio.Writer, n int) {
+	w.WriteString(":" + strconv.Itoa(n) + "\r\n")
+}
@@ -0,0 +1,58 @@
+package sharded
+
+import (
+	"time"
+)
+
+type Options struct {
+	// Shards is the number of independently locked segments
+	Shards int
+	// MaxEntries is the maximum number of entries of all shards, it is
+	// split evenly and the total never exceeds it, Shards is reduced to
+	// MaxEntries when it is smaller, zero means no limit
+	MaxEntries int
+	// CleanupInterval is the interval of removing expired entries in
+	// background, zero means expired entries are only removed on Get
+	CleanupInterval time.Duration
+}
+
+type Option func(*Options)
+
+func newOptions(opts ...Option) Options {
+	option := Options{
+		Shards:     16,
+		MaxEntries: 1000,
+	}
+
+	for _, o := range opts {
+		o(&option)
+	}
+
+	if option.Shards < 1 {
+		option.Shards = 1
+	}
+	// every shard hold at least one entry, zero means no limit to lru
+	if option.MaxEntries > 0 && option.Shards > option.MaxEntries {
+		option.Shards = option.MaxEntries
+	}
+
+	return option
+}
+
+func Shards(shards int) Option {
+	return func(o *Options) {
+		o.Shards = shards
+	}
+}
+
+func MaxEntries(maxEntries int) Option {
+	return func(o *Options) {
+		o.MaxEntries = maxEntries
+	}
+}
+
+func CleanupInterval(cleanupInterval time.Duration) Option {
+	return func(o *Options) {
+		o.CleanupInterval = cleanupInterval
+	}
+}
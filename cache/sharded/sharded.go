@@ -0,0 +1,171 @@
+// Package sharded implements cache by spreading keys across
+// independently locked lru segments, so concurrent operations on
+// different keys rarely contend
+package sharded
+
+import (
+	"time"
+
+	"github.com/haormj/util/cache/evict"
+	"github.com/haormj/util/cache/lru"
+	"github.com/haormj/util/cache/stats"
+)
+
+// ShardedCache implements cache.BaseCache by using sharded memory
+type ShardedCache struct {
+	*Cache[string, interface{}]
+}
+
+func NewShardedCache(opts ...Option) *ShardedCache {
+	shardedCache := &ShardedCache{
+		Cache: New[string, interface{}](opts...),
+	}
+	return shardedCache
+}
+
+// Cache is a type-safe sharded cache, implements cache.TypedCache.
+// every shard is a lru.Cache, keys are hashed by fnv-1a, so K is
+// limited to string kinds
+type Cache[K ~string, V any] struct {
+	option Options
+	shards []*lru.Cache[K, V]
+}
+
+func New[K ~string, V any](opts ...Option) *Cache[K, V] {
+	option := newOptions(opts...)
+
+	c := &Cache[K, V]{
+		option: option,
+		shards: make([]*lru.Cache[K, V], option.Shards),
+	}
+	for i := range c.shards {
+		// spread the remainder, so the total is exactly MaxEntries
+		maxEntries := option.MaxEntries / option.Shards
+		if i < option.MaxEntries%option.Shards {
+			maxEntries++
+		}
+		c.shards[i] = lru.New[K, V](
+			lru.MaxEntries(maxEntries),
+			lru.CleanupInterval(option.CleanupInterval),
+		)
+	}
+	return c
+}
+
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.shard(key).Set(key, value, ttl)
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	return c.shard(key).Get(key)
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.shard(key).Delete(key)
+}
+
+func (c *Cache[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.Clear()
+	}
+}
+
+// Keys return unexpired keys of all shards, in no particular order
+func (c *Cache[K, V]) Keys() []K {
+	var keys []K
+	for _, s := range c.shards {
+		keys = append(keys, s.Keys()...)
+	}
+	return keys
+}
+
+// Len return number of entries of all shards
+func (c *Cache[K, V]) Len() int {
+	var n int
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// DeleteExpired remove all expired entries
+func (c *Cache[K, V]) DeleteExpired() {
+	for _, s := range c.shards {
+		s.DeleteExpired()
+	}
+}
+
+// OnEvict add function called after entry is removed from cache,
+// functions added before are still called
+func (c *Cache[K, V]) OnEvict(f evict.Func[K, V]) {
+	for _, s := range c.shards {
+		s.OnEvict(f)
+	}
+}
+
+// Stats return counters and current item count of all shards
+func (c *Cache[K, V]) Stats() stats.Stats {
+	var total stats.Stats
+	for _, s := range c.shards {
+		st := s.Stats()
+		total.Hits += st.Hits
+		total.Misses += st.Misses
+		total.Sets += st.Sets
+		total.Deletes += st.Deletes
+		total.Evictions += st.Evictions
+		total.Expirations += st.Expirations
+		total.Items += st.Items
+	}
+	return total
+}
+
+// ResetStats set all counters to zero
+func (c *Cache[K, V]) ResetStats() {
+	for _, s := range c.shards {
+		s.ResetStats()
+	}
+}
+
+// Add set value only if key is missing, report whether it is set
+func (c *Cache[K, V]) Add(key K, value V, ttl time.Duration) bool {
+	return c.shard(key).Add(key, value, ttl)
+}
+
+// Replace set value only if key exists, report whether it is set
+func (c *Cache[K, V]) Replace(key K, value V, ttl time.Duration) bool {
+	return c.shard(key).Replace(key, value, ttl)
+}
+
+// CompareAndSwap set new only if current value of key equals old,
+// report whether it is set
+func (c *Cache[K, V]) CompareAndSwap(key K, old, new V, ttl time.Duration) bool {
+	return c.shard(key).CompareAndSwap(key, old, new, ttl)
+}
+
+// Incr add delta to numeric value of key and return the result
+func (c *Cache[K, V]) Incr(key K, delta int64, ttl time.Duration) (int64, error) {
+	return c.shard(key).Incr(key, delta, ttl)
+}
+
+// Decr subtract delta from numeric value of key
+func (c *Cache[K, V]) Decr(key K, delta int64, ttl time.Duration) (int64, error) {
+	return c.shard(key).Decr(key, delta, ttl)
+}
+
+func (c *Cache[K, V]) shard(key K) *lru.Cache[K, V] {
+	return c.shards[fnv32a(string(key))%uint32(len(c.shards))]
+}
+
+// fnv32a is inlined fnv-1a, avoid allocation of hash.Hash32
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
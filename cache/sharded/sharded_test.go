@@ -0,0 +1,122 @@
+package sharded
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haormj/util/cache/lru"
+	"github.com/haormj/util/cache/ttl"
+)
+
+func TestShardedCache(t *testing.T) {
+	c := NewShardedCache(Shards(4), MaxEntries(100))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := strconv.Itoa(i*100 + j)
+				c.Set(key, j, time.Minute)
+				c.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if items := c.Stats().Items; items > 100 {
+		t.Errorf("expected at most 100 items,got:%d", items)
+	}
+	c.Set("a", 1, 0)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected:1,got:%v,%v", v, ok)
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a deleted")
+	}
+	c.Clear()
+	if items := c.Stats().Items; items != 0 {
+		t.Errorf("expected:0,got:%d", items)
+	}
+}
+
+type baseCache interface {
+	Set(string, interface{}, time.Duration)
+	Get(string) (interface{}, bool)
+}
+
+func TestShardedCacheMaxEntries(t *testing.T) {
+	for _, maxEntries := range []int{10, 100} {
+		c := New[string, int](Shards(16), MaxEntries(maxEntries))
+		for i := 0; i < 10000; i++ {
+			c.Set(strconv.Itoa(i), i, 0)
+		}
+		if n := c.Len(); n > maxEntries {
+			t.Errorf("expected at most %d items,got:%d", maxEntries, n)
+		}
+	}
+}
+
+func benchmarkParallelGet(b *testing.B, c baseCache) {
+	const n = 1024
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		c.Set(keys[i], i, time.Hour)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Get(keys[i%n])
+			i++
+		}
+	})
+}
+
+func benchmarkParallelMixed(b *testing.B, c baseCache) {
+	const n = 1024
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		c.Set(keys[i], i, time.Hour)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%10 == 0 {
+				c.Set(keys[i%n], i, time.Hour)
+			} else {
+				c.Get(keys[i%n])
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkShardedGet(b *testing.B) {
+	benchmarkParallelGet(b, NewShardedCache(MaxEntries(2048)))
+}
+
+func BenchmarkLruGet(b *testing.B) {
+	benchmarkParallelGet(b, lru.NewLruCache(lru.MaxEntries(2048)))
+}
+
+func BenchmarkTtlGet(b *testing.B) {
+	benchmarkParallelGet(b, ttl.NewTtlCache())
+}
+
+func BenchmarkShardedMixed(b *testing.B) {
+	benchmarkParallelMixed(b, NewShardedCache(MaxEntries(2048)))
+}
+
+func BenchmarkLruMixed(b *testing.B) {
+	benchmarkParallelMixed(b, lru.NewLruCache(lru.MaxEntries(2048)))
+}
+
+func BenchmarkTtlMixed(b *testing.B) {
+	benchmarkParallelMixed(b, ttl.NewTtlCache())
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"github.com/haormj/util/cache/stats"
+)
+
+// Stats is a snapshot of cache counters
+type Stats = stats.Stats
+
+// statsCollector is implemented by BaseCache which collect counters,
+// such as lru.LruCache and ttl.TtlCache
+type statsCollector interface {
+	Stats() stats.Stats
+	ResetStats()
+}
+
+// Stats return counters of BaseCache, zero Stats is returned when
+// BaseCache does not collect counters
+func (dc defaultCache) Stats() Stats {
+	if sc, ok := dc.BaseCache.(statsCollector); ok {
+		return sc.Stats()
+	}
+	return Stats{}
+}
+
+// ResetStats set all counters of BaseCache to zero
+func (dc defaultCache) ResetStats() {
+	if sc, ok := dc.BaseCache.(statsCollector); ok {
+		sc.ResetStats()
+	}
+}
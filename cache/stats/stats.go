@@ -0,0 +1,85 @@
+// Package stats collect cache counters, shared by cache backends
+package stats
+
+import (
+	"sync/atomic"
+)
+
+// Stats is a snapshot of cache counters
+type Stats struct {
+	Hits        int64
+	Misses      int64
+	Sets        int64
+	Deletes     int64
+	Evictions   int64
+	Expirations int64
+	// Items is current item count, it may include expired items
+	// which are not removed yet
+	Items int64
+}
+
+// HitRatio return hits / (hits + misses)
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Counter is safe for concurrent use
+type Counter struct {
+	hits        int64
+	misses      int64
+	sets        int64
+	deletes     int64
+	evictions   int64
+	expirations int64
+}
+
+func (c *Counter) Hit() {
+	atomic.AddInt64(&c.hits, 1)
+}
+
+func (c *Counter) Miss() {
+	atomic.AddInt64(&c.misses, 1)
+}
+
+func (c *Counter) Set() {
+	atomic.AddInt64(&c.sets, 1)
+}
+
+func (c *Counter) Delete() {
+	atomic.AddInt64(&c.deletes, 1)
+}
+
+func (c *Counter) Evict() {
+	atomic.AddInt64(&c.evictions, 1)
+}
+
+func (c *Counter) Expire() {
+	atomic.AddInt64(&c.expirations, 1)
+}
+
+// Snapshot return current counters with item count
+func (c *Counter) Snapshot(items int64) Stats {
+	return Stats{
+		Hits:        atomic.LoadInt64(&c.hits),
+		Misses:      atomic.LoadInt64(&c.misses),
+		Sets:        atomic.LoadInt64(&c.sets),
+		Deletes:     atomic.LoadInt64(&c.deletes),
+		Evictions:   atomic.LoadInt64(&c.evictions),
+		Expirations: atomic.LoadInt64(&c.expirations),
+		Items:       items,
+	}
+}
+
+// Reset set all counters to zero
+func (c *Counter) Reset() {
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.sets, 0)
+	atomic.StoreInt64(&c.deletes, 0)
+	atomic.StoreInt64(&c.evictions, 0)
+	atomic.StoreInt64(&c.expirations, 0)
+}
@@ -0,0 +1,70 @@
+package tiered
+
+import (
+	"time"
+)
+
+// Policy is how Set reach L2
+type Policy int
+
+const (
+	// WriteThrough write L1 and L2 in Set
+	WriteThrough Policy = iota
+	// WriteBack write L1 in Set, and L2 in background
+	WriteBack
+)
+
+type Options struct {
+	Policy Policy
+	// L1TTL is the maximum ttl of entries in L1, entries promoted from
+	// L2 use it as ttl, so L1 does not outlive L2 for long. zero means
+	// L2 hits are not promoted
+	L1TTL time.Duration
+	// FlushInterval is the interval of writing dirty entries to L2
+	// when Policy is WriteBack
+	FlushInterval time.Duration
+	// FlushSize is the number of dirty entries which trigger writing
+	// to L2 before FlushInterval, zero means only FlushInterval
+	FlushSize int
+}
+
+type Option func(*Options)
+
+func newOptions(opts ...Option) Options {
+	option := Options{
+		Policy:        WriteThrough,
+		L1TTL:         time.Minute,
+		FlushInterval: time.Second,
+		FlushSize:     1000,
+	}
+
+	for _, o := range opts {
+		o(&option)
+	}
+
+	return option
+}
+
+func WithPolicy(policy Policy) Option {
+	return func(o *Options) {
+		o.Policy = policy
+	}
+}
+
+func L1TTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.L1TTL = ttl
+	}
+}
+
+func FlushInterval(flushInterval time.Duration) Option {
+	return func(o *Options) {
+		o.FlushInterval = flushInterval
+	}
+}
+
+func FlushSize(flushSize int) Option {
+	return func(o *Options) {
+		o.FlushSize = flushSize
+	}
+}
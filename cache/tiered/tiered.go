@@ -0,0 +1,327 @@
+// Package tiered implements cache by composing a small fast L1 in front
+// of a larger L2, such as lru in front of ttl
+package tiered
+
+import (
+	"sync"
+	"time"
+
+	"github.com/haormj/util/cache"
+)
+
+// TieredCache implements cache.BaseCache by composing two BaseCache
+type TieredCache struct {
+	*Cache[string, interface{}]
+}
+
+func NewTieredCache(l1, l2 cache.BaseCache, opts ...Option) *TieredCache {
+	tieredCache := &TieredCache{
+		Cache: New[string, interface{}](l1, l2, opts...),
+	}
+	return tieredCache
+}
+
+// Cache is a type-safe tiered cache, implements cache.TypedCache.
+// Get read L1 first, and promote L2 hit into L1.
+// Delete and Clear are applied to both tiers, write-back entries which
+// are not written to L2 yet are dropped
+type Cache[K comparable, V any] struct {
+	l1     cache.TypedCache[K, V]
+	l2     cache.TypedCache[K, V]
+	option Options
+
+	// mu guard the fields below and L1 writes, it is never held while
+	// L2 is accessed, so a slow L2 does not block other keys
+	mu sync.Mutex
+	// dirty hold write-back entries, flushing hold entries being
+	// written to L2 by Flush
+	dirty    map[K]dirtyEntry[V]
+	flushing map[K]dirtyEntry[V]
+	// version is increased before and after every L2 write, L2 is read
+	// without mu, its value is promoted into L1 only if no write
+	// happened in between
+	version uint64
+	// locks serialize L2 writes of the same key, so L1 and L2 agree on
+	// the last value
+	locks map[K]*keyLock
+
+	// clearMu is held exclusively by Clear, and shared by other L2
+	// writes, so no write is interleaved with clearing L2
+	clearMu sync.RWMutex
+	// flushMu serialize Flush
+	flushMu sync.Mutex
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+type dirtyEntry[V any] struct {
+	value      V
+	expiration int64
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func New[K comparable, V any](l1, l2 cache.TypedCache[K, V], opts ...Option) *Cache[K, V] {
+	option := newOptions(opts...)
+
+	c := &Cache[K, V]{
+		l1:       l1,
+		l2:       l2,
+		option:   option,
+		dirty:    make(map[K]dirtyEntry[V]),
+		flushing: make(map[K]dirtyEntry[V]),
+		locks:    make(map[K]*keyLock),
+	}
+	if option.Policy == WriteBack {
+		c.kick = make(chan struct{}, 1)
+		c.stop = make(chan struct{})
+		c.done = make(chan struct{})
+		go c.run()
+	}
+	return c
+}
+
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	if c.option.Policy != WriteBack {
+		c.writeL2(key, func() {
+			c.l2.Set(key, value, ttl)
+		}, func() {
+			c.l1.Set(key, value, c.l1TTL(ttl))
+		})
+		return
+	}
+
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+	c.mu.Lock()
+	c.version++
+	c.dirty[key] = dirtyEntry[V]{value: value, expiration: expiration}
+	c.l1.Set(key, value, c.l1TTL(ttl))
+	full := c.option.FlushSize > 0 && len(c.dirty) >= c.option.FlushSize
+	c.mu.Unlock()
+	if full {
+		select {
+		case c.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	if value, ok := c.l1.Get(key); ok {
+		return value, true
+	}
+	c.mu.Lock()
+	d, ok := c.dirty[key]
+	if !ok {
+		d, ok = c.flushing[key]
+	}
+	if ok {
+		// expired dirty entry hide older value in L2
+		ttl, alive := remaining(d.expiration)
+		if alive {
+			c.l1.Set(key, d.value, c.l1TTL(ttl))
+		}
+		c.mu.Unlock()
+		return d.value, alive
+	}
+	version := c.version
+	c.mu.Unlock()
+
+	value, ok := c.l2.Get(key)
+	if !ok || c.option.L1TTL <= 0 {
+		// ttl left in L2 is unknown, promoted entry without L1TTL
+		// would outlive L2
+		return value, ok
+	}
+	c.mu.Lock()
+	if c.version == version {
+		c.l1.Set(key, value, c.option.L1TTL)
+	}
+	c.mu.Unlock()
+	return value, true
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.writeL2(key, func() {
+		c.mu.Lock()
+		delete(c.dirty, key)
+		delete(c.flushing, key)
+		c.l1.Delete(key)
+		c.mu.Unlock()
+		c.l2.Delete(key)
+	}, func() {})
+}
+
+func (c *Cache[K, V]) Clear() {
+	c.clearMu.Lock()
+	defer c.clearMu.Unlock()
+	c.mu.Lock()
+	c.version++
+	c.dirty = make(map[K]dirtyEntry[V])
+	c.flushing = make(map[K]dirtyEntry[V])
+	c.l1.Clear()
+	c.mu.Unlock()
+	c.l2.Clear()
+	c.mu.Lock()
+	c.version++
+	c.mu.Unlock()
+}
+
+// Keys return keys of both tiers, in no particular order
+func (c *Cache[K, V]) Keys() []K {
+	seen := make(map[K]struct{})
+	var keys []K
+	add := func(key K) {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Lock()
+	for key := range c.dirty {
+		add(key)
+	}
+	for key := range c.flushing {
+		add(key)
+	}
+	c.mu.Unlock()
+	for _, key := range c.l1.Keys() {
+		add(key)
+	}
+	for _, key := range c.l2.Keys() {
+		add(key)
+	}
+	return keys
+}
+
+// Len return number of distinct keys of both tiers
+func (c *Cache[K, V]) Len() int {
+	return len(c.Keys())
+}
+
+// Flush write all dirty entries to L2. dirty entries are swapped out
+// under the lock, and written to L2 without it, so Set is not blocked
+// by L2
+func (c *Cache[K, V]) Flush() {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+	c.mu.Lock()
+	c.flushing, c.dirty = c.dirty, c.flushing
+	keys := make([]K, 0, len(c.flushing))
+	for key := range c.flushing {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+
+	for _, key := range keys {
+		c.writeL2(key, func() {
+			c.mu.Lock()
+			d, ok := c.flushing[key]
+			c.mu.Unlock()
+			if !ok {
+				// deleted or cleared after swapped out
+				return
+			}
+			if ttl, alive := remaining(d.expiration); alive {
+				c.l2.Set(key, d.value, ttl)
+			} else {
+				// older value may be in L2
+				c.l2.Delete(key)
+			}
+		}, func() {
+			delete(c.flushing, key)
+		})
+	}
+}
+
+// Close flush dirty entries and stop background writing,
+// it should be called when Policy is WriteBack
+func (c *Cache[K, V]) Close() {
+	if c.option.Policy != WriteBack {
+		return
+	}
+	c.once.Do(func() {
+		close(c.stop)
+		<-c.done
+		c.Flush()
+	})
+}
+
+// writeL2 call write with lock of key held and mu released, then call
+// after with mu held. version is increased before and after write, so
+// Get never promote a value read while write is in progress
+func (c *Cache[K, V]) writeL2(key K, write, after func()) {
+	c.clearMu.RLock()
+	defer c.clearMu.RUnlock()
+
+	c.mu.Lock()
+	l, ok := c.locks[key]
+	if !ok {
+		l = &keyLock{}
+		c.locks[key] = l
+	}
+	l.refs++
+	c.version++
+	c.mu.Unlock()
+
+	l.mu.Lock()
+	write()
+	c.mu.Lock()
+	c.version++
+	after()
+	c.mu.Unlock()
+	l.mu.Unlock()
+
+	c.mu.Lock()
+	l.refs--
+	if l.refs == 0 {
+		delete(c.locks, key)
+	}
+	c.mu.Unlock()
+}
+
+func (c *Cache[K, V]) run() {
+	defer close(c.done)
+	interval := c.option.FlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.kick:
+			c.Flush()
+		case <-ticker.C:
+			c.Flush()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// l1TTL cap ttl by L1TTL, ttl <= 0 means never expire
+func (c *Cache[K, V]) l1TTL(ttl time.Duration) time.Duration {
+	if c.option.L1TTL > 0 && (ttl <= 0 || ttl > c.option.L1TTL) {
+		return c.option.L1TTL
+	}
+	return ttl
+}
+
+// remaining return ttl left of expiration, zero expiration never expire
+func remaining(expiration int64) (time.Duration, bool) {
+	if expiration == 0 {
+		return 0, true
+	}
+	ttl := time.Duration(expiration - time.Now().UnixNano())
+	return ttl, ttl > 0
+}
@@ -0,0 +1,234 @@
+package tiered
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haormj/util/cache"
+	"github.com/haormj/util/cache/lru"
+	"github.com/haormj/util/cache/ttl"
+)
+
+func TestTieredCache(t *testing.T) {
+	l1 := lru.NewLruCache(lru.MaxEntries(1))
+	l2 := ttl.NewTtlCache()
+	c := NewTieredCache(l1, l2)
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	if _, ok := l1.Get("a"); ok {
+		t.Error("expected a evicted from l1")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected:1,got:%v,%v", v, ok)
+	}
+	if _, ok := l1.Get("a"); !ok {
+		t.Error("expected a promoted to l1")
+	}
+	c.Delete("a")
+	if _, ok := l2.Get("a"); ok {
+		t.Error("expected a deleted from l2")
+	}
+	c.Clear()
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b cleared")
+	}
+
+	var _ cache.BaseCache = c
+}
+
+func TestTieredCacheWriteBack(t *testing.T) {
+	l1 := lru.NewLruCache(lru.MaxEntries(1))
+	l2 := ttl.NewTtlCache()
+	c := NewTieredCache(l1, l2, WithPolicy(WriteBack), FlushInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected:1,got:%v,%v", v, ok)
+	}
+	c.Flush()
+	if v, ok := l2.Get("b"); !ok || v != 2 {
+		t.Errorf("expected:2,got:%v,%v", v, ok)
+	}
+
+	c.Set("c", 3, time.Minute)
+	c.Delete("c")
+	c.Flush()
+	if _, ok := c.Get("c"); ok {
+		t.Error("expected c deleted")
+	}
+}
+
+// hookCache call afterGet after reading the wrapped cache
+type hookCache struct {
+	cache.BaseCache
+	afterGet func()
+}
+
+func (h *hookCache) Get(key string) (interface{}, bool) {
+	v, ok := h.BaseCache.Get(key)
+	if f := h.afterGet; f != nil {
+		h.afterGet = nil
+		f()
+	}
+	return v, ok
+}
+
+func TestTieredCacheDeleteDuringGet(t *testing.T) {
+	l1 := lru.NewLruCache()
+	l2 := &hookCache{BaseCache: ttl.NewTtlCache()}
+	c := NewTieredCache(l1, l2)
+	l2.BaseCache.Set("a", 1, time.Minute)
+
+	// Delete runs in another goroutine between reading L2 and promoting
+	// into L1, the deleted value must not come back
+	l2.afterGet = func() {
+		done := make(chan struct{})
+		go func() {
+			c.Delete("a")
+			close(done)
+		}()
+		<-done
+	}
+	c.Get("a")
+	if v, ok := c.Get("a"); ok {
+		t.Errorf("expected a deleted,got:%v", v)
+	}
+	if _, ok := l1.Get("a"); ok {
+		t.Error("expected a not promoted into l1")
+	}
+}
+
+func TestTieredCacheConcurrent(t *testing.T) {
+	l1 := lru.NewLruCache()
+	l2 := ttl.NewTtlCache()
+	c := NewTieredCache(l1, l2)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				switch j % 3 {
+				case 0:
+					c.Set("a", i, time.Minute)
+				case 1:
+					c.Get("a")
+				default:
+					c.Delete("a")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// tiers agree after concurrent writes
+	v1, ok1 := l1.Get("a")
+	v2, ok2 := l2.Get("a")
+	if ok1 && (!ok2 || v1 != v2) {
+		t.Errorf("expected l1 consistent with l2,got:%v,%v and %v,%v", v1, ok1, v2, ok2)
+	}
+}
+
+func TestTieredCacheFlushSize(t *testing.T) {
+	l1 := lru.NewLruCache()
+	l2 := ttl.NewTtlCache()
+	c := NewTieredCache(l1, l2, WithPolicy(WriteBack), FlushInterval(time.Hour), FlushSize(3))
+	defer c.Close()
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	time.Sleep(time.Millisecond * 20)
+	if n := l2.Len(); n != 0 {
+		t.Errorf("expected dirty entries kept before FlushSize,got:%d in l2", n)
+	}
+	c.Set("c", 3, time.Minute)
+	time.Sleep(time.Millisecond * 20)
+	if n := l2.Len(); n != 3 {
+		t.Errorf("expected:3 flushed at FlushSize,got:%d", n)
+	}
+}
+
+func TestTieredCacheNoL1TTL(t *testing.T) {
+	l1 := lru.NewLruCache()
+	l2 := ttl.NewTtlCache()
+	c := NewTieredCache(l1, l2, L1TTL(0))
+	l2.Set("a", 1, time.Millisecond*20)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected:1,got:%v,%v", v, ok)
+	}
+	time.Sleep(time.Millisecond * 30)
+	if v, ok := c.Get("a"); ok {
+		t.Errorf("expected a expired with l2,got:%v", v)
+	}
+}
+
+func TestTieredCacheExpiredDirty(t *testing.T) {
+	l1 := lru.NewLruCache()
+	l2 := ttl.NewTtlCache()
+	c := NewTieredCache(l1, l2, WithPolicy(WriteBack), FlushInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("a", "old", time.Hour)
+	c.Flush()
+	c.Set("a", "new", time.Millisecond*20)
+	time.Sleep(time.Millisecond * 30)
+	if v, ok := c.Get("a"); ok {
+		t.Errorf("expected a expired before flush,got:%v", v)
+	}
+	c.Flush()
+	if v, ok := c.Get("a"); ok {
+		t.Errorf("expected a expired after flush,got:%v", v)
+	}
+	if _, ok := l2.Get("a"); ok {
+		t.Error("expected old value deleted from l2")
+	}
+}
+
+// slowCache block Set until release is closed
+type slowCache struct {
+	cache.BaseCache
+	release chan struct{}
+}
+
+func (s *slowCache) Set(key string, value interface{}, ttl time.Duration) {
+	<-s.release
+	s.BaseCache.Set(key, value, ttl)
+}
+
+func TestTieredCacheSlowL2(t *testing.T) {
+	l1 := lru.NewLruCache()
+	l2 := &slowCache{BaseCache: ttl.NewTtlCache(), release: make(chan struct{})}
+	c := NewTieredCache(l1, l2, WithPolicy(WriteBack), FlushInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1, time.Minute)
+	flushed := make(chan struct{})
+	go func() {
+		c.Flush()
+		close(flushed)
+	}()
+
+	// Flush is blocked by L2, Set, Get and Delete of other keys are not
+	done := make(chan struct{})
+	go func() {
+		c.Set("b", 2, time.Minute)
+		c.Delete("c")
+		if v, ok := c.Get("a"); !ok || v != 1 {
+			t.Errorf("expected:1,got:%v,%v", v, ok)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected operations not blocked by slow l2")
+	}
+	close(l2.release)
+	<-flushed
+	if v, ok := l2.Get("a"); !ok || v != 1 {
+		t.Errorf("expected:1,got:%v,%v", v, ok)
+	}
+}
@@ -0,0 +1,84 @@
+package tinylfu
+
+// sketch is count-min sketch with 4 rows of counters saturated at 15,
+// counters are halved after sampleSize additions, so old popularity
+// fade out. every row has 8 counters per entry to reduce collision
+type sketch struct {
+	rows       [4][]uint8
+	mask       uint32
+	additions  int
+	sampleSize int
+}
+
+func newSketch(capacity int) *sketch {
+	width := 16
+	for width < 8*capacity {
+		width <<= 1
+	}
+	s := &sketch{
+		mask:       uint32(width - 1),
+		sampleSize: 10 * capacity,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *sketch) increment(h uint64) {
+	h1, h2 := uint32(h), uint32(h>>32)
+	for i := range s.rows {
+		idx := (h1 + uint32(i)*h2) & s.mask
+		if s.rows[i][idx] < 15 {
+			s.rows[i][idx]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.reset()
+	}
+}
+
+func (s *sketch) estimate(h uint64) uint8 {
+	h1, h2 := uint32(h), uint32(h>>32)
+	min := uint8(15)
+	for i := range s.rows {
+		idx := (h1 + uint32(i)*h2) & s.mask
+		if s.rows[i][idx] < min {
+			min = s.rows[i][idx]
+		}
+	}
+	return min
+}
+
+func (s *sketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
+
+func (s *sketch) clear() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] = 0
+		}
+	}
+	s.additions = 0
+}
+
+// fnv64a is inlined fnv-1a, avoid allocation of hash.Hash64
+func fnv64a(s string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime64
+	}
+	return h
+}
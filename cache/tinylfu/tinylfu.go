@@ -0,0 +1,162 @@
+// Package tinylfu implements W-TinyLFU cache, new entries stay in a small
+// lru window, and enter the segmented lru main cache only when they
+// are estimated more frequent than the entry they replace, so a scan
+// hardly pollute the main cache.
+// https://arxiv.org/abs/1512.00727
+package tinylfu
+
+import (
+	"container/list"
+	"runtime"
+
+	"github.com/haormj/util/cache/internal/bounded"
+	"github.com/haormj/util/cache/internal/janitor"
+)
+
+// TinyLfuCache implements cache.BaseCache by using memory
+type TinyLfuCache struct {
+	*Cache[string, interface{}]
+}
+
+func NewTinyLfuCache(opts ...Option) *TinyLfuCache {
+	tinyLfuCache := &TinyLfuCache{
+		Cache: New[string, interface{}](opts...),
+	}
+	return tinyLfuCache
+}
+
+// Cache is a type-safe W-TinyLFU cache, implements cache.TypedCache.
+// keys are hashed by fnv-1a, so K is limited to string kinds
+type Cache[K ~string, V any] struct {
+	*bounded.Cache[K, V]
+	// janitor only hold bounded.Cache, so Cache can be garbage
+	// collected and the finalizer stop the janitor
+	janitor *janitor.Janitor
+}
+
+func New[K ~string, V any](opts ...Option) *Cache[K, V] {
+	option := newOptions(opts...)
+
+	c := &Cache[K, V]{
+		Cache: bounded.New[K, V](newPolicy[K](option.MaxEntries)),
+	}
+	if option.CleanupInterval > 0 {
+		c.janitor = janitor.Run(option.CleanupInterval, c.Cache.DeleteExpired)
+		runtime.SetFinalizer(c, stopJanitor[K, V])
+	}
+	return c
+}
+
+func stopJanitor[K ~string, V any](c *Cache[K, V]) {
+	c.janitor.Stop()
+}
+
+// policy split keys into window (1%), probation and protected (80% of
+// main), front of list is MRU
+type policy[K ~string] struct {
+	windowCap    int
+	mainCap      int
+	protectedCap int
+	window       *list.List
+	probation    *list.List
+	protected    *list.List
+	entries      map[K]*entry
+	sketch       *sketch
+}
+
+type entry struct {
+	list *list.List
+	elem *list.Element
+}
+
+func newPolicy[K ~string](maxEntries int) *policy[K] {
+	windowCap := maxEntries / 100
+	if windowCap < 1 {
+		windowCap = 1
+	}
+	mainCap := maxEntries - windowCap
+	return &policy[K]{
+		windowCap:    windowCap,
+		mainCap:      mainCap,
+		protectedCap: mainCap * 8 / 10,
+		window:       list.New(),
+		probation:    list.New(),
+		protected:    list.New(),
+		entries:      make(map[K]*entry),
+		sketch:       newSketch(maxEntries),
+	}
+}
+
+func (p *policy[K]) Add(key K) []K {
+	p.sketch.increment(fnv64a(string(key)))
+	p.entries[key] = &entry{list: p.window, elem: p.window.PushFront(key)}
+	if p.window.Len() <= p.windowCap {
+		return nil
+	}
+
+	candidate := p.window.Back().Value.(K)
+	if p.probation.Len()+p.protected.Len() < p.mainCap {
+		p.move(candidate, p.probation)
+		return nil
+	}
+	victimList := p.probation
+	if victimList.Len() == 0 {
+		victimList = p.protected
+	}
+	if victimList.Len() == 0 {
+		p.Remove(candidate)
+		return []K{candidate}
+	}
+	victim := victimList.Back().Value.(K)
+	if p.sketch.estimate(fnv64a(string(candidate))) > p.sketch.estimate(fnv64a(string(victim))) {
+		p.Remove(victim)
+		p.move(candidate, p.probation)
+		return []K{victim}
+	}
+	p.Remove(candidate)
+	return []K{candidate}
+}
+
+func (p *policy[K]) Access(key K) {
+	p.sketch.increment(fnv64a(string(key)))
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+	switch e.list {
+	case p.window, p.protected:
+		e.list.MoveToFront(e.elem)
+	case p.probation:
+		p.move(key, p.protected)
+		if p.protected.Len() > p.protectedCap {
+			p.move(p.protected.Back().Value.(K), p.probation)
+		}
+	}
+}
+
+func (p *policy[K]) Miss(key K) {
+	p.sketch.increment(fnv64a(string(key)))
+}
+
+func (p *policy[K]) Remove(key K) {
+	if e, ok := p.entries[key]; ok {
+		e.list.Remove(e.elem)
+		delete(p.entries, key)
+	}
+}
+
+func (p *policy[K]) Clear() {
+	p.window.Init()
+	p.probation.Init()
+	p.protected.Init()
+	p.entries = make(map[K]*entry)
+	p.sketch.clear()
+}
+
+// move key to the front of l
+func (p *policy[K]) move(key K, l *list.List) {
+	e := p.entries[key]
+	e.list.Remove(e.elem)
+	e.list = l
+	e.elem = l.PushFront(key)
+}
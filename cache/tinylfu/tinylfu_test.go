@@ -0,0 +1,39 @@
+package tinylfu
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTinyLfuCache(t *testing.T) {
+	c := NewTinyLfuCache(MaxEntries(100))
+	for i := 0; i < 10; i++ {
+		key := strconv.Itoa(i)
+		c.Set(key, i, 0)
+		for j := 0; j < 5; j++ {
+			c.Get(key)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		c.Set("scan"+strconv.Itoa(i), i, 0)
+	}
+	for i := 0; i < 10; i++ {
+		if v, ok := c.Get(strconv.Itoa(i)); !ok || v != i {
+			t.Errorf("expected:%d,got:%v,%v", i, v, ok)
+		}
+	}
+	if items := c.Stats().Items; items != 100 {
+		t.Errorf("expected:100,got:%d", items)
+	}
+}
+
+func TestTinyLfuCacheCleanup(t *testing.T) {
+	c := New[string, int](CleanupInterval(time.Millisecond * 20))
+	c.Set("a", 1, time.Millisecond*10)
+	c.Set("b", 2, time.Minute)
+	time.Sleep(time.Millisecond * 100)
+	if n := c.Len(); n != 1 {
+		t.Errorf("expected:1,got:%d", n)
+	}
+}
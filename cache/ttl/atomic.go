@@ -0,0 +1,72 @@
+package ttl
+
+import (
+	"time"
+
+	"github.com/haormj/util/cache/internal/entry"
+)
+
+// store adapt Cache to entry.Store, so atomic operations are shared
+// with other backends
+type store[K ~string, V any] struct {
+	*Cache[K, V]
+}
+
+func (s store[K, V]) Lock() {
+	s.atomicMu.Lock()
+}
+
+func (s store[K, V]) Unlock() {
+	s.atomicMu.Unlock()
+}
+
+func (s store[K, V]) Lookup(key K) (entry.Item[V], bool) {
+	v, expiration, ok := s.cache.GetWithExpiration(string(key))
+	if !ok {
+		return entry.Item[V]{}, false
+	}
+	i := entry.Item[V]{Value: toValue[V](v)}
+	if !expiration.IsZero() {
+		i.Expiration = expiration.UnixNano()
+	}
+	return i, true
+}
+
+func (s store[K, V]) Set(key K, value V, expiration int64) {
+	var ttl time.Duration
+	if expiration > 0 {
+		ttl = time.Until(time.Unix(0, expiration))
+		if ttl <= 0 {
+			ttl = time.Nanosecond
+		}
+	}
+	s.stats.Set()
+	s.cache.Set(string(key), value, ttl)
+}
+
+// Add set value only if key is missing, report whether it is set
+func (c *Cache[K, V]) Add(key K, value V, ttl time.Duration) bool {
+	return entry.Add[K, V](store[K, V]{c}, key, value, ttl)
+}
+
+// Replace set value only if key exists, report whether it is set
+func (c *Cache[K, V]) Replace(key K, value V, ttl time.Duration) bool {
+	return entry.Replace[K, V](store[K, V]{c}, key, value, ttl)
+}
+
+// CompareAndSwap set new only if current value of key equals old,
+// report whether it is set
+func (c *Cache[K, V]) CompareAndSwap(key K, old, new V, ttl time.Duration) bool {
+	return entry.CompareAndSwap[K, V](store[K, V]{c}, key, old, new, ttl)
+}
+
+// Incr add delta to numeric value of key and return the result.
+// missing key is set to delta with ttl, otherwise its expiration is kept
+func (c *Cache[K, V]) Incr(key K, delta int64, ttl time.Duration) (int64, error) {
+	return entry.Incr[K, V](store[K, V]{c}, key, delta, ttl)
+}
+
+// Decr subtract delta from numeric value of key, same as Incr
+func (c *Cache[K, V]) Decr(key K, delta int64, ttl time.Duration) (int64, error) {
+	return entry.Decr[K, V](store[K, V]{c}, key, delta, ttl)
+}
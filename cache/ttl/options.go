@@ -2,10 +2,20 @@ package ttl
 
 import (
 	"time"
+
+	"github.com/haormj/util/cache/codec"
 )
 
 type Options struct {
 	CleanupInterval time.Duration
+	// Codec serialize values in snapshot
+	Codec codec.Codec
+	// SnapshotFile is loaded in NewTtlCache and saved in Close,
+	// empty means no snapshot
+	SnapshotFile string
+	// SnapshotInterval is the interval of saving SnapshotFile,
+	// zero means only saving in Close
+	SnapshotInterval time.Duration
 }
 
 type Option func(*Options)
@@ -13,6 +23,7 @@ type Option func(*Options)
 func newOptions(opts ...Option) Options {
 	option := Options{
 		CleanupInterval: time.Minute * 10,
+		Codec:           codec.Gob,
 	}
 
 	for _, o := range opts {
@@ -27,3 +38,21 @@ func CleanupInterval(cleanupInterval time.Duration) Option {
 		o.CleanupInterval = cleanupInterval
 	}
 }
+
+func Codec(c codec.Codec) Option {
+	return func(o *Options) {
+		o.Codec = c
+	}
+}
+
+func SnapshotFile(snapshotFile string) Option {
+	return func(o *Options) {
+		o.SnapshotFile = snapshotFile
+	}
+}
+
+func SnapshotInterval(snapshotInterval time.Duration) Option {
+	return func(o *Options) {
+		o.SnapshotInterval = snapshotInterval
+	}
+}
@@ -0,0 +1,162 @@
+package ttl
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/haormj/util/log"
+)
+
+const snapshotVersion = 1
+
+type snapshotHeader struct {
+	Version int
+	Count   int
+}
+
+type snapshotRecord struct {
+	Key        string
+	Value      []byte
+	Expiration int64
+}
+
+// Save write unexpired entries with their expiration to w,
+// values are serialized by Codec, entries failed to serialize are
+// skipped and logged, so one bad value never lose the whole snapshot
+func (c *Cache[K, V]) Save(w io.Writer) error {
+	items := c.cache.Items()
+	records := make([]snapshotRecord, 0, len(items))
+	for key, item := range items {
+		value := toValue[V](item.Object)
+		data, err := c.option.Codec.Marshal(&value)
+		if err != nil {
+			log.Error("snapshot skip ", key, ": marshal failed: ", err)
+			continue
+		}
+		records = append(records, snapshotRecord{
+			Key:        key,
+			Value:      data,
+			Expiration: item.Expiration,
+		})
+	}
+
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion, Count: len(records)}); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load read entries from r which is written by Save, entries expired
+// in the meantime are skipped
+func (c *Cache[K, V]) Load(r io.Reader) error {
+	dec := gob.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	if header.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version: %d", header.Version)
+	}
+	for i := 0; i < header.Count; i++ {
+		var record snapshotRecord
+		if err := dec.Decode(&record); err != nil {
+			return err
+		}
+		var ttl time.Duration
+		if record.Expiration > 0 {
+			ttl = time.Duration(record.Expiration - time.Now().UnixNano())
+			if ttl <= 0 {
+				continue
+			}
+		}
+		var value V
+		if err := c.option.Codec.Unmarshal(record.Value, &value); err != nil {
+			return fmt.Errorf("unmarshal %s: %w", record.Key, err)
+		}
+		c.cache.Set(record.Key, value, ttl)
+	}
+	return nil
+}
+
+// SaveFile write snapshot to a temporary file, then rename it to
+// filename, so filename is never partially written
+func (c *Cache[K, V]) SaveFile(filename string) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	if err := c.Save(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// LoadFile read snapshot from filename
+func (c *Cache[K, V]) LoadFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
+
+// Close stop saving SnapshotFile in background, and save it at last
+func (c *Cache[K, V]) Close() error {
+	if c.option.SnapshotFile == "" {
+		return nil
+	}
+	c.closeOnce.Do(func() {
+		if c.stop != nil {
+			close(c.stop)
+			<-c.done
+		}
+	})
+	return c.SaveFile(c.option.SnapshotFile)
+}
+
+func (c *Cache[K, V]) restore() {
+	err := c.LoadFile(c.option.SnapshotFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error("load snapshot ", c.option.SnapshotFile, " failed: ", err)
+	}
+	if c.option.SnapshotInterval <= 0 {
+		return
+	}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go c.runSnapshot()
+}
+
+func (c *Cache[K, V]) runSnapshot() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.option.SnapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.SaveFile(c.option.SnapshotFile); err != nil {
+				log.Error("save snapshot ", c.option.SnapshotFile, " failed: ", err)
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
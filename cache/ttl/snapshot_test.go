@@ -0,0 +1,64 @@
+package ttl
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	c := NewTtlCache(SnapshotFile(filename))
+	c.Set("a", "hello", time.Minute)
+	c.Set("b", 1, 0)
+	c.Set("c", "expired", time.Millisecond)
+	time.Sleep(time.Millisecond * 10)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = NewTtlCache(SnapshotFile(filename))
+	if v, ok := c.Get("a"); !ok || v != "hello" {
+		t.Errorf("expected:hello,got:%v,%v", v, ok)
+	}
+	if v, ok := c.Get("b"); !ok || v != 1 {
+		t.Errorf("expected:1,got:%v,%v", v, ok)
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Error("expected c expired")
+	}
+
+	typed := New[string, int]()
+	typed.Set("n", 42, time.Minute)
+	if err := typed.SaveFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	typed = New[string, int]()
+	if err := typed.LoadFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := typed.Get("n"); !ok || v != 42 {
+		t.Errorf("expected:42,got:%v,%v", v, ok)
+	}
+}
+
+func TestSnapshotSkipBadValue(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	c := NewTtlCache(SnapshotFile(filename))
+	c.Set("a", "hello", time.Minute)
+	// unregistered concrete type can not be encoded by gob
+	c.Set("bad", struct{ X int }{1}, time.Minute)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = NewTtlCache(SnapshotFile(filename))
+	if v, ok := c.Get("a"); !ok || v != "hello" {
+		t.Errorf("expected:hello,got:%v,%v", v, ok)
+	}
+	if _, ok := c.Get("bad"); ok {
+		t.Error("expected bad skipped")
+	}
+}
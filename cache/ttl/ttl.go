@@ -1,39 +1,199 @@
 package ttl
 
 import (
+	"sync"
 	"time"
 
+	"github.com/haormj/util/cache/evict"
+	"github.com/haormj/util/cache/stats"
 	pc "github.com/patrickmn/go-cache"
 )
 
+// TtlCache implements cache.BaseCache by using go-cache
 type TtlCache struct {
+	*Cache[string, interface{}]
+}
+
+func NewTtlCache(opts ...Option) *TtlCache {
+	ttlCache := &TtlCache{
+		Cache: New[string, interface{}](opts...),
+	}
+	return ttlCache
+}
+
+// Cache is a type-safe ttl cache, implements cache.TypedCache.
+// go-cache is keyed by string, so K is limited to string kinds
+type Cache[K ~string, V any] struct {
 	cache  *pc.Cache
 	option Options
+	stats  stats.Counter
+
+	// atomicMu is held exclusively by atomic operations, which are
+	// not all supported by go-cache, other writes share it
+	atomicMu sync.RWMutex
+
+	mu      sync.Mutex
+	onEvict evict.Func[K, V]
+	// go-cache report both Delete and expiration by OnEvicted,
+	// keys being removed by Delete or Clear are recorded here, and
+	// their values are reported after atomicMu is released
+	removing map[string]removing
+
+	// stop and done control saving SnapshotFile in background
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
-func NewTtlCache(opts ...Option) *TtlCache {
+type removing struct {
+	reason  evict.Reason
+	n       int
+	pending []interface{}
+}
+
+func New[K ~string, V any](opts ...Option) *Cache[K, V] {
 	option := newOptions(opts...)
 
-	ttlCache := &TtlCache{
-		option: option,
-		cache:  pc.New(0, option.CleanupInterval),
+	c := &Cache[K, V]{
+		option:   option,
+		cache:    pc.New(0, option.CleanupInterval),
+		removing: make(map[string]removing),
 	}
-	return ttlCache
+	c.cache.OnEvicted(c.onEvicted)
+	if option.SnapshotFile != "" {
+		c.restore()
+	}
+	return c
+}
+
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.stats.Set()
+	c.atomicMu.RLock()
+	c.cache.Set(string(key), value, ttl)
+	c.atomicMu.RUnlock()
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	v, ok := c.cache.Get(string(key))
+	if !ok {
+		c.stats.Miss()
+		var value V
+		return value, false
+	}
+	c.stats.Hit()
+	return toValue[V](v), true
 }
 
-func (tc *TtlCache) Set(key string, value interface{}, ttl time.Duration) {
-	tc.cache.Set(key, value, ttl)
+func (c *Cache[K, V]) Delete(key K) {
+	c.stats.Delete()
+	c.remove(string(key), evict.Deleted)
+}
+
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	onEvict := c.onEvict
+	c.mu.Unlock()
+	// go-cache Flush does not call OnEvicted
+	if onEvict == nil {
+		c.atomicMu.RLock()
+		c.cache.Flush()
+		c.atomicMu.RUnlock()
+		return
+	}
+	for key := range c.cache.Items() {
+		c.remove(key, evict.Cleared)
+	}
+}
+
+// Keys return unexpired keys, in no particular order
+func (c *Cache[K, V]) Keys() []K {
+	items := c.cache.Items()
+	keys := make([]K, 0, len(items))
+	for key := range items {
+		keys = append(keys, K(key))
+	}
+	return keys
+}
+
+// Len return number of entries, it may include expired entries which
+// are not removed yet
+func (c *Cache[K, V]) Len() int {
+	return c.cache.ItemCount()
+}
+
+// OnEvict add function called after entry is removed from cache,
+// functions added before are still called.
+// expired entries are reported when they are removed by janitor
+func (c *Cache[K, V]) OnEvict(f evict.Func[K, V]) {
+	c.mu.Lock()
+	c.onEvict = evict.Chain(c.onEvict, f)
+	c.mu.Unlock()
+}
 
+// Stats return counters and current item count, expirations are
+// counted when expired entries are removed by janitor
+func (c *Cache[K, V]) Stats() stats.Stats {
+	return c.stats.Snapshot(int64(c.cache.ItemCount()))
 }
 
-func (tc *TtlCache) Get(key string) (interface{}, bool) {
-	return tc.cache.Get(key)
+// ResetStats set all counters to zero
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.Reset()
 }
 
-func (tc *TtlCache) Delete(key string) {
-	tc.cache.Delete(key)
+func (c *Cache[K, V]) onEvicted(key string, value interface{}) {
+	c.mu.Lock()
+	r, ok := c.removing[key]
+	if ok {
+		r.pending = append(r.pending, value)
+		c.removing[key] = r
+		c.mu.Unlock()
+		return
+	}
+	onEvict := c.onEvict
+	c.mu.Unlock()
+	c.stats.Expire()
+	if onEvict != nil {
+		onEvict(K(key), toValue[V](value), evict.Expired)
+	}
 }
 
-func (tc *TtlCache) Clear() {
-	tc.cache.Flush()
+func (c *Cache[K, V]) remove(key string, reason evict.Reason) {
+	c.mu.Lock()
+	r := c.removing[key]
+	r.reason = reason
+	r.n++
+	c.removing[key] = r
+	c.mu.Unlock()
+
+	c.atomicMu.RLock()
+	c.cache.Delete(key)
+	c.atomicMu.RUnlock()
+
+	c.mu.Lock()
+	r = c.removing[key]
+	pending := r.pending
+	r.pending = nil
+	r.n--
+	if r.n == 0 {
+		delete(c.removing, key)
+	} else {
+		c.removing[key] = r
+	}
+	onEvict := c.onEvict
+	c.mu.Unlock()
+
+	if onEvict != nil {
+		for _, value := range pending {
+			onEvict(K(key), toValue[V](value), r.reason)
+		}
+	}
+}
+
+func toValue[V any](v interface{}) V {
+	var value V
+	if v != nil {
+		value = v.(V)
+	}
+	return value
 }
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"time"
+)
+
+// NewTypedCache wrap BaseCache as TypedCache, values which are not V
+// are treated as missing
+func NewTypedCache[V any](baseCache BaseCache) TypedCache[string, V] {
+	return typedCache[V]{
+		baseCache: baseCache,
+	}
+}
+
+type typedCache[V any] struct {
+	baseCache BaseCache
+}
+
+func (tc typedCache[V]) Set(key string, value V, ttl time.Duration) {
+	tc.baseCache.Set(key, value, ttl)
+}
+
+func (tc typedCache[V]) Get(key string) (V, bool) {
+	var value V
+	v, ok := tc.baseCache.Get(key)
+	if !ok || v == nil {
+		return value, ok
+	}
+	value, ok = v.(V)
+	return value, ok
+}
+
+func (tc typedCache[V]) Delete(key string) {
+	tc.baseCache.Delete(key)
+}
+
+func (tc typedCache[V]) Keys() []string {
+	return tc.baseCache.Keys()
+}
+
+func (tc typedCache[V]) Len() int {
+	return tc.baseCache.Len()
+}
+
+func (tc typedCache[V]) Clear() {
+	tc.baseCache.Clear()
+}
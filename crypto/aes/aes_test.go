@@ -20,3 +20,99 @@ func TestCBC(t *testing.T) {
 		t.Errorf("expected:%v,got:%v", plainText, pt)
 	}
 }
+
+func TestGCM(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	plainText := []byte("AES GCM")
+	additionalData := []byte("header")
+	cipherText, err := GCMEncrypt(plainText, key, additionalData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, err := GCMDecrypt(cipherText, key, additionalData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pt, plainText) {
+		t.Errorf("expected:%v,got:%v", plainText, pt)
+	}
+
+	if _, err := GCMDecrypt(cipherText, key, []byte("other")); err != ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", ErrAuthentication, err)
+	}
+	cipherText[len(cipherText)-1] ^= 1
+	if _, err := GCMDecrypt(cipherText, key, additionalData); err != ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", ErrAuthentication, err)
+	}
+	if _, err := GCMDecrypt(cipherText[:10], key, additionalData); err != ErrCipherTextTooShort {
+		t.Errorf("expected:%v,got:%v", ErrCipherTextTooShort, err)
+	}
+}
+
+func TestCBCRandomIV(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	plainText := []byte("AES CBC PKCS7")
+	c1, err := CBCEncryptRandomIV(plainText, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := CBCEncryptRandomIV(plainText, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(c1, c2) {
+		t.Error("expected different cipher text for the same plain text")
+	}
+	for _, c := range [][]byte{c1, c2} {
+		pt, err := CBCDecryptRandomIV(c, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pt, plainText) {
+			t.Errorf("expected:%v,got:%v", plainText, pt)
+		}
+	}
+}
+
+func TestCBCWithIV(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	plainText := []byte("AES CBC PKCS7")
+	legacy, err := CBCEncrypt(plainText, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipherText, err := CBCEncryptWithIV(plainText, key, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(legacy, cipherText) {
+		t.Errorf("expected:%v,got:%v", legacy, cipherText)
+	}
+	if _, err := CBCEncryptWithIV(plainText, key, key[:8]); err != ErrInvalidIV {
+		t.Errorf("expected:%v,got:%v", ErrInvalidIV, err)
+	}
+}
+
+func TestCBCDecryptInvalid(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	if _, err := CBCDecrypt(nil, key); err != ErrCipherTextTooShort {
+		t.Errorf("expected:%v,got:%v", ErrCipherTextTooShort, err)
+	}
+	if _, err := CBCDecrypt(make([]byte, 17), key); err != ErrInvalidLength {
+		t.Errorf("expected:%v,got:%v", ErrInvalidLength, err)
+	}
+	if _, err := CBCDecryptRandomIV(make([]byte, 8), key); err != ErrCipherTextTooShort {
+		t.Errorf("expected:%v,got:%v", ErrCipherTextTooShort, err)
+	}
+}
+
+func TestCBCKeepPlainText(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	buf := []byte("0123456789abcdef")
+	if _, err := CBCEncryptRandomIV(buf[:5], key); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "0123456789abcdef" {
+		t.Errorf("expected plain text untouched,got:%q", buf)
+	}
+}
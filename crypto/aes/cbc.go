@@ -3,28 +3,74 @@ package aes
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"errors"
+
+	"github.com/haormj/util"
 )
 
-func CBCEncrypt(plainText, key []byte) ([]byte, error) {
+// ErrInvalidIV is returned when iv length is not equal to block size
+var ErrInvalidIV = errors.New("aes: iv length must equal block size")
+
+// CBCEncryptRandomIV encrypt plainText with a random iv, the iv is
+// prepended to the cipher text, so the same plainText never produce
+// the same cipher text
+func CBCEncryptRandomIV(plainText, key []byte) ([]byte, error) {
+	iv, err := util.RandomBytesE(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := CBCEncryptWithIV(plainText, key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return append(iv, cipherText...), nil
+}
+
+// CBCDecryptRandomIV decrypt cipher text produced by CBCEncryptRandomIV
+func CBCDecryptRandomIV(cipherText, key []byte) ([]byte, error) {
+	if len(cipherText) < aes.BlockSize {
+		return nil, ErrCipherTextTooShort
+	}
+	return CBCDecryptWithIV(cipherText[aes.BlockSize:], key, cipherText[:aes.BlockSize])
+}
+
+// CBCEncryptWithIV encrypt plainText with the given iv, the iv is not
+// included in the cipher text. it is used to interop with peers which
+// exchange iv out of band
+func CBCEncryptWithIV(plainText, key, iv []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
-	plainText = PKCS7Padding(plainText, block.BlockSize())
-	blockModel := cipher.NewCBCEncrypter(block, key[:block.BlockSize()])
+	if len(iv) != block.BlockSize() {
+		return nil, ErrInvalidIV
+	}
+	// copy before padding, PKCS7Padding append into the backing array
+	// of plainText which belongs to caller
+	plainText = PKCS7Padding(append(make([]byte, 0, len(plainText)+block.BlockSize()), plainText...), block.BlockSize())
+	blockModel := cipher.NewCBCEncrypter(block, iv)
 	cipherText := make([]byte, len(plainText))
 	blockModel.CryptBlocks(cipherText, plainText)
 	return cipherText, nil
 }
 
-func CBCDecrypt(cipherText, key []byte) ([]byte, error) {
+// CBCDecryptWithIV decrypt cipher text produced by CBCEncryptWithIV
+func CBCDecryptWithIV(cipherText, key, iv []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
-	blockModel := cipher.NewCBCDecrypter(block, key[:block.BlockSize()])
+	if len(iv) != block.BlockSize() {
+		return nil, ErrInvalidIV
+	}
+	if len(cipherText) == 0 {
+		return nil, ErrCipherTextTooShort
+	}
+	if len(cipherText)%block.BlockSize() != 0 {
+		return nil, ErrInvalidLength
+	}
+	blockModel := cipher.NewCBCDecrypter(block, iv)
 	plainText := make([]byte, len(cipherText))
 	blockModel.CryptBlocks(plainText, cipherText)
-	plainText = PKCS7UnPadding(plainText, block.BlockSize())
-	return plainText, nil
+	return PKCS7UnPadding(plainText, block.BlockSize())
 }
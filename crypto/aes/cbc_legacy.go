@@ -0,0 +1,27 @@
+package aes
+
+import "crypto/aes"
+
+// legacy cbc functions use key as iv, they are kept for backward
+// compatibility with existing cipher text only
+
+// CBCEncrypt encrypt plainText with key[:blockSize] as iv
+//
+// Deprecated: the same plainText always produce the same cipher text and
+// the key is used as iv, use CBCEncryptRandomIV or GCMEncrypt instead
+func CBCEncrypt(plainText, key []byte) ([]byte, error) {
+	if len(key) < aes.BlockSize {
+		return nil, aes.KeySizeError(len(key))
+	}
+	return CBCEncryptWithIV(plainText, key, key[:aes.BlockSize])
+}
+
+// CBCDecrypt decrypt cipher text produced by CBCEncrypt
+//
+// Deprecated: use CBCDecryptRandomIV or GCMDecrypt instead
+func CBCDecrypt(cipherText, key []byte) ([]byte, error) {
+	if len(key) < aes.BlockSize {
+		return nil, aes.KeySizeError(len(key))
+	}
+	return CBCDecryptWithIV(cipherText, key, key[:aes.BlockSize])
+}
@@ -0,0 +1,174 @@
+package aes
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/haormj/util"
+)
+
+// envelope format
+//
+//	version(1) | algorithm(1) | kdf(1) | iterations(4) |
+//	salt size(1) | salt | nonce size(1) | nonce | cipher text
+//
+// everything before cipher text is the additional data of gcm, so
+// parameters can not be modified without detection. Open reads the
+// parameters from the envelope, so changing defaults of Seal never
+// breaks existing envelopes
+
+const envelopeVersion = 1
+
+// Algorithm is the cipher of envelope
+type Algorithm byte
+
+const (
+	AES128GCM Algorithm = iota + 1
+	AES192GCM
+	AES256GCM
+)
+
+func (a Algorithm) keySize() int {
+	switch a {
+	case AES128GCM:
+		return 16
+	case AES192GCM:
+		return 24
+	case AES256GCM:
+		return 32
+	}
+	return 0
+}
+
+// KDF is the key derivation function of envelope
+type KDF byte
+
+const (
+	PBKDF2SHA256 KDF = iota + 1
+	PBKDF2SHA512
+)
+
+func (k KDF) derive(passphrase, salt []byte, iter, keyLen int) ([]byte, error) {
+	switch k {
+	case PBKDF2SHA256:
+		return PBKDF2(passphrase, salt, iter, keyLen, sha256.New), nil
+	case PBKDF2SHA512:
+		return PBKDF2(passphrase, salt, iter, keyLen, sha512.New), nil
+	}
+	return nil, ErrUnsupportedKDF
+}
+
+// maxIterations limits the work of Open on untrusted envelope
+const maxIterations = 10000000
+
+var (
+	// ErrInvalidEnvelope is returned when envelope is malformed
+	ErrInvalidEnvelope = errors.New("aes: invalid envelope")
+	// ErrUnsupportedVersion is returned when envelope version is unknown
+	ErrUnsupportedVersion = errors.New("aes: unsupported envelope version")
+	// ErrUnsupportedAlgorithm is returned when algorithm is unknown
+	ErrUnsupportedAlgorithm = errors.New("aes: unsupported algorithm")
+	// ErrUnsupportedKDF is returned when kdf is unknown
+	ErrUnsupportedKDF = errors.New("aes: unsupported kdf")
+	// ErrInvalidIterations is returned when iteration count is out of range
+	ErrInvalidIterations = errors.New("aes: invalid kdf iterations")
+	// ErrInvalidSaltSize is returned when salt size is out of range
+	ErrInvalidSaltSize = errors.New("aes: invalid salt size")
+)
+
+// Seal derive a key from passphrase with a random salt, encrypt plainText
+// and return a self describing envelope
+func Seal(plainText, passphrase []byte, opts ...SealOption) ([]byte, error) {
+	option := newSealOptions(opts...)
+	keySize := option.Algorithm.keySize()
+	if keySize == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if option.Iterations <= 0 || option.Iterations > maxIterations {
+		return nil, ErrInvalidIterations
+	}
+	if option.SaltSize <= 0 || option.SaltSize > 255 {
+		return nil, ErrInvalidSaltSize
+	}
+	salt, err := util.RandomBytesE(option.SaltSize)
+	if err != nil {
+		return nil, err
+	}
+	key, err := option.KDF.derive(passphrase, salt, option.Iterations, keySize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := util.RandomBytesE(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 9+len(salt)+len(nonce))
+	header = append(header, envelopeVersion, byte(option.Algorithm), byte(option.KDF))
+	header = append(header, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(header[3:7], uint32(option.Iterations))
+	header = append(header, byte(len(salt)))
+	header = append(header, salt...)
+	header = append(header, byte(len(nonce)))
+	header = append(header, nonce...)
+	return aead.Seal(header, nonce, plainText, header), nil
+}
+
+// Open decrypt envelope produced by Seal with passphrase, ErrAuthentication
+// is returned when passphrase is wrong or envelope is modified
+func Open(envelope, passphrase []byte) ([]byte, error) {
+	if len(envelope) < 1 {
+		return nil, ErrInvalidEnvelope
+	}
+	if envelope[0] != envelopeVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if len(envelope) < 8 {
+		return nil, ErrInvalidEnvelope
+	}
+	algorithm, kdf := Algorithm(envelope[1]), KDF(envelope[2])
+	iter := binary.BigEndian.Uint32(envelope[3:7])
+	saltSize := int(envelope[7])
+	rest := envelope[8:]
+	if len(rest) < saltSize+1 {
+		return nil, ErrInvalidEnvelope
+	}
+	salt := rest[:saltSize]
+	nonceSize := int(rest[saltSize])
+	rest = rest[saltSize+1:]
+	if len(rest) < nonceSize {
+		return nil, ErrInvalidEnvelope
+	}
+	nonce, cipherText := rest[:nonceSize], rest[nonceSize:]
+	header := envelope[:len(envelope)-len(cipherText)]
+
+	keySize := algorithm.keySize()
+	if keySize == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if iter == 0 || iter > maxIterations {
+		return nil, ErrInvalidIterations
+	}
+	key, err := kdf.derive(passphrase, salt, int(iter), keySize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if nonceSize != aead.NonceSize() || len(cipherText) < aead.Overhead() {
+		return nil, ErrInvalidEnvelope
+	}
+	plainText, err := aead.Open(nil, nonce, cipherText, header)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return plainText, nil
+}
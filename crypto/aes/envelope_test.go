@@ -0,0 +1,62 @@
+package aes
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	plainText := []byte("AES envelope")
+	for _, opts := range [][]SealOption{
+		{Iterations(1000)},
+		{Iterations(1000), WithAlgorithm(AES128GCM), WithKDF(PBKDF2SHA512), SaltSize(32)},
+	} {
+		envelope, err := Seal(plainText, passphrase, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pt, err := Open(envelope, passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pt, plainText) {
+			t.Errorf("expected:%v,got:%v", plainText, pt)
+		}
+	}
+}
+
+func TestEnvelopeInvalid(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	envelope, err := Seal([]byte("AES envelope"), passphrase, Iterations(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(envelope, []byte("wrong")); err != ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", ErrAuthentication, err)
+	}
+	// lowering iterations is detected
+	modified := append([]byte{}, envelope...)
+	modified[6]--
+	if _, err := Open(modified, passphrase); err != ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", ErrAuthentication, err)
+	}
+	modified = append([]byte{}, envelope...)
+	modified[0] = 2
+	if _, err := Open(modified, passphrase); err != ErrUnsupportedVersion {
+		t.Errorf("expected:%v,got:%v", ErrUnsupportedVersion, err)
+	}
+	modified = append([]byte{}, envelope...)
+	modified[1] = 9
+	if _, err := Open(modified, passphrase); err != ErrUnsupportedAlgorithm {
+		t.Errorf("expected:%v,got:%v", ErrUnsupportedAlgorithm, err)
+	}
+	for i := 0; i < len(envelope)-16; i++ {
+		if _, err := Open(envelope[:i], passphrase); err == nil {
+			t.Errorf("expected error for truncated envelope of %d bytes", i)
+		}
+	}
+	if _, err := Seal(nil, passphrase, Iterations(0)); err != ErrInvalidIterations {
+		t.Errorf("expected:%v,got:%v", ErrInvalidIterations, err)
+	}
+}
@@ -0,0 +1,59 @@
+package aes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+
+	"github.com/haormj/util"
+)
+
+var (
+	// ErrAuthentication is returned when cipher text or additional data
+	// is modified, or key is wrong
+	ErrAuthentication = errors.New("aes: message authentication failed")
+	// ErrCipherTextTooShort is returned when cipher text is shorter than
+	// nonce and tag
+	ErrCipherTextTooShort = errors.New("aes: cipher text too short")
+)
+
+// GCMEncrypt encrypt and authenticate plainText and additionalData,
+// random nonce is prepended to the cipher text. additionalData is
+// optional, and must be the same in GCMDecrypt
+func GCMEncrypt(plainText, key, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := util.RandomBytesE(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plainText, additionalData), nil
+}
+
+// GCMDecrypt decrypt cipher text produced by GCMEncrypt, ErrAuthentication
+// is returned when authentication fails
+func GCMDecrypt(cipherText, key, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherText) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrCipherTextTooShort
+	}
+	nonce, cipherText := cipherText[:aead.NonceSize()], cipherText[aead.NonceSize():]
+	plainText, err := aead.Open(nil, nonce, cipherText, additionalData)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return plainText, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
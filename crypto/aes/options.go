@@ -0,0 +1,79 @@
+package aes
+
+type StreamOptions struct {
+	// ChunkSize is the size of plain text sealed in each chunk, it is
+	// written to the stream header, so the reader does not need it
+	ChunkSize int
+}
+
+type StreamOption func(*StreamOptions)
+
+func newStreamOptions(opts ...StreamOption) StreamOptions {
+	option := StreamOptions{
+		ChunkSize: 64 * 1024,
+	}
+
+	for _, o := range opts {
+		o(&option)
+	}
+
+	return option
+}
+
+func ChunkSize(chunkSize int) StreamOption {
+	return func(o *StreamOptions) {
+		o.ChunkSize = chunkSize
+	}
+}
+
+type SealOptions struct {
+	// Algorithm is the cipher used to seal the envelope
+	Algorithm Algorithm
+	// KDF is the function deriving key from passphrase
+	KDF KDF
+	// Iterations is the iteration count of kdf
+	Iterations int
+	// SaltSize is the size of random salt in bytes
+	SaltSize int
+}
+
+type SealOption func(*SealOptions)
+
+func newSealOptions(opts ...SealOption) SealOptions {
+	option := SealOptions{
+		Algorithm:  AES256GCM,
+		KDF:        PBKDF2SHA256,
+		Iterations: 600000,
+		SaltSize:   16,
+	}
+
+	for _, o := range opts {
+		o(&option)
+	}
+
+	return option
+}
+
+func WithAlgorithm(algorithm Algorithm) SealOption {
+	return func(o *SealOptions) {
+		o.Algorithm = algorithm
+	}
+}
+
+func WithKDF(kdf KDF) SealOption {
+	return func(o *SealOptions) {
+		o.KDF = kdf
+	}
+}
+
+func Iterations(iterations int) SealOption {
+	return func(o *SealOptions) {
+		o.Iterations = iterations
+	}
+}
+
+func SaltSize(saltSize int) SealOption {
+	return func(o *SealOptions) {
+		o.SaltSize = saltSize
+	}
+}
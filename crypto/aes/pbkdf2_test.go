@@ -0,0 +1,34 @@
+package aes
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	cases := []struct {
+		h        func() hash.Hash
+		password string
+		salt     string
+		iter     int
+		keyLen   int
+		expected string
+	}{
+		// rfc 6070
+		{sha1.New, "password", "salt", 1, 20, "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{sha1.New, "password", "salt", 4096, 20, "4b007901b765489abead49d926f721d065a429c1"},
+		{sha1.New, "passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 25,
+			"3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038"},
+		{sha256.New, "password", "salt", 1, 32, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{sha256.New, "password", "salt", 4096, 32, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+	for _, c := range cases {
+		dk := PBKDF2([]byte(c.password), []byte(c.salt), c.iter, c.keyLen, c.h)
+		if got := hex.EncodeToString(dk); got != c.expected {
+			t.Errorf("expected:%v,got:%v", c.expected, got)
+		}
+	}
+}
@@ -2,6 +2,18 @@ package aes
 
 import (
 	"bytes"
+	"errors"
+)
+
+var (
+	// ErrInvalidBlockSize is returned when block size is not in 1..255
+	ErrInvalidBlockSize = errors.New("aes: invalid block size")
+	// ErrEmptyPlainText is returned when unpadding empty input
+	ErrEmptyPlainText = errors.New("aes: empty plain text")
+	// ErrInvalidLength is returned when input is not a multiple of block size
+	ErrInvalidLength = errors.New("aes: input not a multiple of block size")
+	// ErrInvalidPadding is returned when pkcs7 padding is malformed
+	ErrInvalidPadding = errors.New("aes: invalid pkcs7 padding")
 )
 
 func PKCS7Padding(ciphertext []byte, blockSize int) []byte {
@@ -10,8 +22,27 @@ func PKCS7Padding(ciphertext []byte, blockSize int) []byte {
 	return append(ciphertext, padtext...)
 }
 
-func PKCS7UnPadding(plantText []byte, blockSize int) []byte {
+// PKCS7UnPadding remove pkcs7 padding, the pad value must be in
+// 1..blockSize and all padding bytes must equal to it
+func PKCS7UnPadding(plantText []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 || blockSize > 255 {
+		return nil, ErrInvalidBlockSize
+	}
 	length := len(plantText)
+	if length == 0 {
+		return nil, ErrEmptyPlainText
+	}
+	if length%blockSize != 0 {
+		return nil, ErrInvalidLength
+	}
 	unpadding := int(plantText[length-1])
-	return plantText[:(length - unpadding)]
+	if unpadding == 0 || unpadding > blockSize {
+		return nil, ErrInvalidPadding
+	}
+	for _, b := range plantText[length-unpadding:] {
+		if int(b) != unpadding {
+			return nil, ErrInvalidPadding
+		}
+	}
+	return plantText[:(length - unpadding)], nil
 }
@@ -0,0 +1,85 @@
+package aes
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPKCS7UnPadding(t *testing.T) {
+	cases := []struct {
+		in  []byte
+		out []byte
+		err error
+	}{
+		{nil, nil, ErrEmptyPlainText},
+		{[]byte{1, 2, 3}, nil, ErrInvalidLength},
+		{[]byte{1, 2, 3, 0}, nil, ErrInvalidPadding},
+		{[]byte{1, 2, 3, 5}, nil, ErrInvalidPadding},
+		{[]byte{1, 2, 1, 2}, nil, ErrInvalidPadding},
+		{[]byte{1, 2, 2, 2}, []byte{1, 2}, nil},
+		{[]byte{4, 4, 4, 4}, []byte{}, nil},
+	}
+	for _, c := range cases {
+		out, err := PKCS7UnPadding(c.in, 4)
+		if err != c.err {
+			t.Errorf("%v: expected:%v,got:%v", c.in, c.err, err)
+		}
+		if !bytes.Equal(out, c.out) {
+			t.Errorf("%v: expected:%v,got:%v", c.in, c.out, out)
+		}
+	}
+	if _, err := PKCS7UnPadding([]byte{1}, 0); err != ErrInvalidBlockSize {
+		t.Errorf("expected:%v,got:%v", ErrInvalidBlockSize, err)
+	}
+}
+
+func FuzzPKCS7UnPadding(f *testing.F) {
+	f.Add([]byte{}, 16)
+	f.Add([]byte{1, 2, 2, 2}, 4)
+	f.Add(bytes.Repeat([]byte{16}, 16), 16)
+	f.Fuzz(func(t *testing.T, data []byte, blockSize int) {
+		out, err := PKCS7UnPadding(data, blockSize)
+		if err != nil {
+			return
+		}
+		if len(out) >= len(data) || len(data)-len(out) > blockSize {
+			t.Fatalf("unexpected unpadding %v -> %v", data, out)
+		}
+		if !bytes.Equal(PKCS7Padding(out, blockSize), data) {
+			t.Fatalf("padding roundtrip mismatch %v", data)
+		}
+	})
+}
+
+func FuzzPKCS7Roundtrip(f *testing.F) {
+	f.Add([]byte("AES CBC PKCS7"), 16)
+	f.Fuzz(func(t *testing.T, data []byte, blockSize int) {
+		if blockSize <= 0 || blockSize > 255 {
+			return
+		}
+		padded := PKCS7Padding(append([]byte{}, data...), blockSize)
+		out, err := PKCS7UnPadding(padded, blockSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, data) {
+			t.Fatalf("expected:%v,got:%v", data, out)
+		}
+	})
+}
+
+func FuzzCBCDecrypt(f *testing.F) {
+	key := []byte("0123456789abcdef")
+	cipherText, err := CBCEncryptRandomIV([]byte("AES CBC PKCS7"), key)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(cipherText)
+	f.Add([]byte{})
+	f.Add(make([]byte, 17))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// must not panic on arbitrary input
+		CBCDecryptRandomIV(data, key)
+		CBCDecrypt(data, key)
+	})
+}
@@ -0,0 +1,239 @@
+package aes
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/haormj/util"
+)
+
+// stream format
+//
+//	header: magic(4) | version(1) | chunk size(4) | nonce prefix(7)
+//	chunks: gcm sealed chunk ...
+//
+// every chunk except the last one holds exactly chunk size bytes of plain
+// text, the last one holds less (maybe zero). chunk nonce is nonce prefix
+// | counter(4) | last flag(1), and the header is the additional data of
+// every chunk, so reordering, truncating and appending chunks are detected
+
+const (
+	streamVersion    = 1
+	streamPrefixSize = 7
+	streamHeaderSize = 4 + 1 + 4 + streamPrefixSize
+	maxChunkSize     = 16 * 1024 * 1024
+)
+
+var streamMagic = []byte("AESS")
+
+var (
+	// ErrInvalidHeader is returned when stream header is malformed
+	ErrInvalidHeader = errors.New("aes: invalid stream header")
+	// ErrTruncated is returned when stream ends before the last chunk
+	ErrTruncated = errors.New("aes: stream truncated")
+	// ErrInvalidChunkSize is returned when chunk size is out of range
+	ErrInvalidChunkSize = errors.New("aes: invalid chunk size")
+	// ErrTooManyChunks is returned when chunk counter overflows
+	ErrTooManyChunks = errors.New("aes: too many chunks")
+	// ErrClosed is returned when writing to a closed stream
+	ErrClosed = errors.New("aes: write to closed stream")
+)
+
+type streamCipher struct {
+	aead    cipher.AEAD
+	header  []byte
+	nonce   []byte
+	counter uint32
+}
+
+func newStreamCipher(key, header []byte) (*streamCipher, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, header[streamHeaderSize-streamPrefixSize:])
+	return &streamCipher{
+		aead:   aead,
+		header: header,
+		nonce:  nonce,
+	}, nil
+}
+
+func (s *streamCipher) next(last bool) ([]byte, error) {
+	if s.counter == ^uint32(0) {
+		return nil, ErrTooManyChunks
+	}
+	binary.BigEndian.PutUint32(s.nonce[streamPrefixSize:], s.counter)
+	s.nonce[len(s.nonce)-1] = 0
+	if last {
+		s.nonce[len(s.nonce)-1] = 1
+	}
+	s.counter++
+	return s.nonce, nil
+}
+
+type encryptWriter struct {
+	w      io.Writer
+	sc     *streamCipher
+	buf    []byte
+	out    []byte
+	closed bool
+}
+
+// NewEncryptWriter returns a writer which encrypt data written to it with
+// chunked aes gcm and write to w, Close must be called to write the last
+// chunk, Close does not close w
+func NewEncryptWriter(w io.Writer, key []byte, opts ...StreamOption) (io.WriteCloser, error) {
+	option := newStreamOptions(opts...)
+	if option.ChunkSize <= 0 || option.ChunkSize > maxChunkSize {
+		return nil, ErrInvalidChunkSize
+	}
+	header := make([]byte, streamHeaderSize)
+	copy(header, streamMagic)
+	header[4] = streamVersion
+	binary.BigEndian.PutUint32(header[5:9], uint32(option.ChunkSize))
+	prefix, err := util.RandomBytesE(streamPrefixSize)
+	if err != nil {
+		return nil, err
+	}
+	copy(header[9:], prefix)
+	sc, err := newStreamCipher(key, header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:   w,
+		sc:  sc,
+		buf: make([]byte, 0, option.ChunkSize),
+		out: make([]byte, 0, option.ChunkSize+sc.aead.Overhead()),
+	}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrClosed
+	}
+	n := 0
+	for len(p) > 0 {
+		// flush only when more data arrives, so the last chunk is
+		// always shorter than chunk size
+		if len(e.buf) == cap(e.buf) {
+			if err := e.flush(false); err != nil {
+				return n, err
+			}
+		}
+		m := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	if len(e.buf) == cap(e.buf) {
+		if err := e.flush(false); err != nil {
+			return err
+		}
+	}
+	e.closed = true
+	return e.flush(true)
+}
+
+func (e *encryptWriter) flush(last bool) error {
+	nonce, err := e.sc.next(last)
+	if err != nil {
+		return err
+	}
+	e.out = e.sc.aead.Seal(e.out[:0], nonce, e.buf, e.sc.header)
+	e.buf = e.buf[:0]
+	_, err = e.w.Write(e.out)
+	return err
+}
+
+type decryptReader struct {
+	r    io.Reader
+	sc   *streamCipher
+	in   []byte
+	buf  []byte
+	done bool
+	err  error
+}
+
+// NewDecryptReader returns a reader which decrypt stream produced by
+// NewEncryptWriter from r, data of a chunk is returned only after it is
+// authenticated, ErrTruncated is returned if r ends before the last chunk
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrInvalidHeader
+		}
+		return nil, err
+	}
+	if string(header[:4]) != string(streamMagic) || header[4] != streamVersion {
+		return nil, ErrInvalidHeader
+	}
+	chunkSize := binary.BigEndian.Uint32(header[5:9])
+	if chunkSize == 0 || chunkSize > maxChunkSize {
+		return nil, ErrInvalidHeader
+	}
+	sc, err := newStreamCipher(key, header)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		r:  r,
+		sc: sc,
+		in: make([]byte, int(chunkSize)+sc.aead.Overhead()),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.buf, d.err = d.next()
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptReader) next() ([]byte, error) {
+	n, err := io.ReadFull(d.r, d.in)
+	last := false
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return nil, err
+	}
+	if n < d.sc.aead.Overhead() {
+		return nil, ErrTruncated
+	}
+	nonce, err := d.sc.next(last)
+	if err != nil {
+		return nil, err
+	}
+	plainText, err := d.sc.aead.Open(d.in[:0], nonce, d.in[:n], d.sc.header)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	d.done = last
+	return plainText, nil
+}
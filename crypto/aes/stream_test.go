@@ -0,0 +1,93 @@
+package aes
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/haormj/util"
+)
+
+func encryptStream(t *testing.T, plainText, key []byte, opts ...StreamOption) []byte {
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, key, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// write in odd sized pieces to cross chunk boundary
+	for p := plainText; len(p) > 0; {
+		n := 7
+		if n > len(p) {
+			n = len(p)
+		}
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatal(err)
+		}
+		p = p[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(cipherText, key []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(cipherText), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStream(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	for _, size := range []int{0, 1, 63, 64, 65, 128, 1000} {
+		plainText, err := util.RandomBytesE(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cipherText := encryptStream(t, plainText, key, ChunkSize(64))
+		pt, err := decryptStream(cipherText, key)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(pt, plainText) {
+			t.Errorf("size %d: expected:%v,got:%v", size, plainText, pt)
+		}
+	}
+}
+
+func TestStreamTamper(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	plainText := bytes.Repeat([]byte("a"), 200)
+	cipherText := encryptStream(t, plainText, key, ChunkSize(64))
+	chunk := 64 + 16
+
+	// truncated at chunk boundary
+	if _, err := decryptStream(cipherText[:streamHeaderSize+chunk], key); err != ErrTruncated {
+		t.Errorf("expected:%v,got:%v", ErrTruncated, err)
+	}
+	// truncated in the middle of chunk
+	if _, err := decryptStream(cipherText[:len(cipherText)-1], key); err != ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", ErrAuthentication, err)
+	}
+	// swapped chunks
+	swapped := append([]byte{}, cipherText...)
+	copy(swapped[streamHeaderSize:], cipherText[streamHeaderSize+chunk:streamHeaderSize+2*chunk])
+	copy(swapped[streamHeaderSize+chunk:], cipherText[streamHeaderSize:streamHeaderSize+chunk])
+	if _, err := decryptStream(swapped, key); err != ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", ErrAuthentication, err)
+	}
+	// modified header
+	modified := append([]byte{}, cipherText...)
+	modified[streamHeaderSize-1] ^= 1
+	if _, err := decryptStream(modified, key); err != ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", ErrAuthentication, err)
+	}
+	if _, err := decryptStream(cipherText[:3], key); err != ErrInvalidHeader {
+		t.Errorf("expected:%v,got:%v", ErrInvalidHeader, err)
+	}
+	if _, err := decryptStream(cipherText, []byte("fedcba9876543210")); err != ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", ErrAuthentication, err)
+	}
+}
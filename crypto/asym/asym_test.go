@@ -0,0 +1,111 @@
+package asym
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"testing"
+)
+
+func generateKeys(t *testing.T) []crypto.PrivateKey {
+	rsaKey, err := GenerateRSAKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, err := GenerateECDSAKey(elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := GenerateECDSAKey(elliptic.P384())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, err := GenerateEd25519Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []crypto.PrivateKey{rsaKey, p256, p384, ed}
+}
+
+func TestSignVerify(t *testing.T) {
+	p := []byte("hello world")
+	for _, priv := range generateKeys(t) {
+		pub, err := PublicKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := Sign(p, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Verify(p, sig, pub); err != nil {
+			t.Errorf("%T: %v", priv, err)
+		}
+		if err := Verify([]byte("hello world!"), sig, pub); err != ErrVerification {
+			t.Errorf("%T: expected:%v,got:%v", priv, ErrVerification, err)
+		}
+	}
+	if _, err := Sign(p, "key"); err != ErrUnsupportedKey {
+		t.Errorf("expected:%v,got:%v", ErrUnsupportedKey, err)
+	}
+}
+
+func TestPEM(t *testing.T) {
+	p := []byte("hello world")
+	for _, priv := range generateKeys(t) {
+		pub, err := PublicKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privPEM, err := MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubPEM, err := MarshalPKIXPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		priv2, err := ParsePrivateKey(privPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub2, err := ParsePublicKey(pubPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := Sign(p, priv2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Verify(p, sig, pub2); err != nil {
+			t.Errorf("%T: %v", priv, err)
+		}
+		if _, err := ParsePKIXPublicKey(privPEM); err != ErrUnexpectedPEMType {
+			t.Errorf("expected:%v,got:%v", ErrUnexpectedPEMType, err)
+		}
+	}
+	if _, err := ParsePrivateKey([]byte("not pem")); err != ErrInvalidPEM {
+		t.Errorf("expected:%v,got:%v", ErrInvalidPEM, err)
+	}
+}
+
+func TestPKCS1(t *testing.T) {
+	key, err := GenerateRSAKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ParsePKCS1PrivateKey(MarshalPKCS1PrivateKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Error("expected equal private key")
+	}
+	pub, err := ParsePublicKey(MarshalPKCS1PublicKey(&key.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.(*rsa.PublicKey).Equal(&key.PublicKey) {
+		t.Error("expected equal public key")
+	}
+}
@@ -0,0 +1,3 @@
+// Package asym generate asymmetric keys, read and write them as pem, and
+// sign or verify with rsa-pss, ecdsa and ed25519
+package asym
@@ -0,0 +1,157 @@
+package asym
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+)
+
+// pem block types
+const (
+	TypePKCS1PrivateKey = "RSA PRIVATE KEY"
+	TypePKCS1PublicKey  = "RSA PUBLIC KEY"
+	TypePKCS8PrivateKey = "PRIVATE KEY"
+	TypePKIXPublicKey   = "PUBLIC KEY"
+	TypeECPrivateKey    = "EC PRIVATE KEY"
+)
+
+var (
+	// ErrInvalidPEM is returned when no pem block is found
+	ErrInvalidPEM = errors.New("asym: invalid pem")
+	// ErrUnexpectedPEMType is returned when pem block type does not match
+	ErrUnexpectedPEMType = errors.New("asym: unexpected pem type")
+	// ErrUnsupportedKey is returned when key type is not rsa, ecdsa or
+	// ed25519
+	ErrUnsupportedKey = errors.New("asym: unsupported key type")
+)
+
+// MarshalPKCS1PrivateKey encode rsa private key as pkcs #1 pem
+func MarshalPKCS1PrivateKey(key *rsa.PrivateKey) []byte {
+	return encodePEM(TypePKCS1PrivateKey, x509.MarshalPKCS1PrivateKey(key))
+}
+
+// ParsePKCS1PrivateKey decode pkcs #1 pem into rsa private key
+func ParsePKCS1PrivateKey(p []byte) (*rsa.PrivateKey, error) {
+	der, err := decodePEM(p, TypePKCS1PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKCS1PrivateKey(der)
+}
+
+// MarshalPKCS1PublicKey encode rsa public key as pkcs #1 pem
+func MarshalPKCS1PublicKey(key *rsa.PublicKey) []byte {
+	return encodePEM(TypePKCS1PublicKey, x509.MarshalPKCS1PublicKey(key))
+}
+
+// ParsePKCS1PublicKey decode pkcs #1 pem into rsa public key
+func ParsePKCS1PublicKey(p []byte) (*rsa.PublicKey, error) {
+	der, err := decodePEM(p, TypePKCS1PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKCS1PublicKey(der)
+}
+
+// MarshalPKCS8PrivateKey encode rsa, ecdsa or ed25519 private key as
+// pkcs #8 pem
+func MarshalPKCS8PrivateKey(key crypto.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return encodePEM(TypePKCS8PrivateKey, der), nil
+}
+
+// ParsePKCS8PrivateKey decode pkcs #8 pem into *rsa.PrivateKey,
+// *ecdsa.PrivateKey or ed25519.PrivateKey
+func ParsePKCS8PrivateKey(p []byte) (crypto.PrivateKey, error) {
+	der, err := decodePEM(p, TypePKCS8PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKCS8PrivateKey(der)
+}
+
+// MarshalPKIXPublicKey encode rsa, ecdsa or ed25519 public key as pkix pem
+func MarshalPKIXPublicKey(key crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return encodePEM(TypePKIXPublicKey, der), nil
+}
+
+// ParsePKIXPublicKey decode pkix pem into *rsa.PublicKey,
+// *ecdsa.PublicKey or ed25519.PublicKey
+func ParsePKIXPublicKey(p []byte) (crypto.PublicKey, error) {
+	der, err := decodePEM(p, TypePKIXPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKIXPublicKey(der)
+}
+
+// ParsePrivateKey decode pkcs #1, pkcs #8 or sec 1 ec pem into private key
+func ParsePrivateKey(p []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(p)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+	switch block.Type {
+	case TypePKCS1PrivateKey:
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case TypePKCS8PrivateKey:
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case TypeECPrivateKey:
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	return nil, ErrUnexpectedPEMType
+}
+
+// ParsePublicKey decode pkix or pkcs #1 pem into public key
+func ParsePublicKey(p []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(p)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+	switch block.Type {
+	case TypePKIXPublicKey:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case TypePKCS1PublicKey:
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return nil, ErrUnexpectedPEMType
+}
+
+// PublicKey returns public key of rsa, ecdsa or ed25519 private key
+func PublicKey(key crypto.PrivateKey) (crypto.PublicKey, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey, nil
+	case ed25519.PrivateKey:
+		return k.Public(), nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+func encodePEM(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+func decodePEM(p []byte, typ string) ([]byte, error) {
+	block, _ := pem.Decode(p)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+	if block.Type != typ {
+		return nil, ErrUnexpectedPEMType
+	}
+	return block.Bytes, nil
+}
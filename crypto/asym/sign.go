@@ -0,0 +1,114 @@
+package asym
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+)
+
+// ErrVerification is returned when signature does not match
+var ErrVerification = errors.New("asym: verification failed")
+
+// SignRSAPSS sign sha256 digest of p with rsa-pss
+func SignRSAPSS(p []byte, key *rsa.PrivateKey) ([]byte, error) {
+	digest := sha256.Sum256(p)
+	return rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], nil)
+}
+
+// VerifyRSAPSS verify rsa-pss signature of p
+func VerifyRSAPSS(p, sig []byte, key *rsa.PublicKey) error {
+	digest := sha256.Sum256(p)
+	if err := rsa.VerifyPSS(key, crypto.SHA256, digest[:], sig, nil); err != nil {
+		return ErrVerification
+	}
+	return nil
+}
+
+// SignECDSA sign digest of p with ecdsa, the signature is asn.1 encoded,
+// the hash is sha256, sha384 or sha512 according to curve size
+func SignECDSA(p []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	digest, err := ecdsaDigest(p, key.Curve.Params().BitSize)
+	if err != nil {
+		return nil, err
+	}
+	return ecdsa.SignASN1(rand.Reader, key, digest)
+}
+
+// VerifyECDSA verify asn.1 encoded ecdsa signature of p
+func VerifyECDSA(p, sig []byte, key *ecdsa.PublicKey) error {
+	digest, err := ecdsaDigest(p, key.Curve.Params().BitSize)
+	if err != nil {
+		return err
+	}
+	if !ecdsa.VerifyASN1(key, digest, sig) {
+		return ErrVerification
+	}
+	return nil
+}
+
+// SignEd25519 sign p with ed25519
+func SignEd25519(p []byte, key ed25519.PrivateKey) ([]byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, ErrUnsupportedKey
+	}
+	return ed25519.Sign(key, p), nil
+}
+
+// VerifyEd25519 verify ed25519 signature of p
+func VerifyEd25519(p, sig []byte, key ed25519.PublicKey) error {
+	if len(key) != ed25519.PublicKeySize {
+		return ErrUnsupportedKey
+	}
+	if !ed25519.Verify(key, p, sig) {
+		return ErrVerification
+	}
+	return nil
+}
+
+// Sign sign p by type of key, rsa keys use rsa-pss
+func Sign(p []byte, key crypto.PrivateKey) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return SignRSAPSS(p, k)
+	case *ecdsa.PrivateKey:
+		return SignECDSA(p, k)
+	case ed25519.PrivateKey:
+		return SignEd25519(p, k)
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// Verify verify signature of p by type of key, rsa keys use rsa-pss
+func Verify(p, sig []byte, key crypto.PublicKey) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return VerifyRSAPSS(p, sig, k)
+	case *ecdsa.PublicKey:
+		return VerifyECDSA(p, sig, k)
+	case ed25519.PublicKey:
+		return VerifyEd25519(p, sig, k)
+	}
+	return ErrUnsupportedKey
+}
+
+func ecdsaDigest(p []byte, bitSize int) ([]byte, error) {
+	var h hash.Hash
+	switch {
+	case bitSize <= 256:
+		h = sha256.New()
+	case bitSize <= 384:
+		h = sha512.New384()
+	default:
+		h = sha512.New()
+	}
+	if _, err := h.Write(p); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -1 +1,150 @@
+// Package crypto select symmetric ciphers by name, so the algorithm can be
+// changed from config without code changes
 package crypto
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/haormj/util/crypto/aes"
+)
+
+// Cipher encrypt and decrypt with a raw key
+type Cipher interface {
+	Name() string
+	Encrypt(plainText, key []byte, opts ...Option) ([]byte, error)
+	Decrypt(cipherText, key []byte, opts ...Option) ([]byte, error)
+}
+
+// AEAD is implemented by ciphers which authenticate additional data,
+// AEAD returns true if AdditionalData option is supported
+type AEAD interface {
+	Cipher
+	AEAD() bool
+}
+
+// names of builtin ciphers
+const (
+	// AESGCM is aes gcm with random nonce prepended
+	AESGCM = "aes-gcm"
+	// AESCBC is aes cbc pkcs7 with random iv prepended, it is not
+	// authenticated, prefer AESGCM
+	AESCBC = "aes-cbc"
+)
+
+var (
+	// ErrUnknownCipher is returned when cipher is not registered
+	ErrUnknownCipher = errors.New("crypto: unknown cipher")
+	// ErrAdditionalDataNotSupported is returned when additional data is
+	// given to a cipher without authentication
+	ErrAdditionalDataNotSupported = errors.New("crypto: additional data not supported")
+)
+
+var (
+	mu      sync.RWMutex
+	ciphers = make(map[string]Cipher)
+)
+
+func init() {
+	Register(aesGCM{})
+	Register(aesCBC{})
+}
+
+// Register make cipher available by its name, it panics if cipher is nil
+// or the name is registered twice. other implementations such as
+// chacha20-poly1305 can be registered from their own package init
+func Register(c Cipher) {
+	if c == nil {
+		panic("crypto: Register cipher is nil")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := ciphers[c.Name()]; ok {
+		panic("crypto: Register called twice for cipher " + c.Name())
+	}
+	ciphers[c.Name()] = c
+}
+
+// Get returns the cipher registered with name
+func Get(name string) (Cipher, error) {
+	mu.RLock()
+	c, ok := ciphers[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownCipher
+	}
+	return c, nil
+}
+
+// Names returns sorted names of registered ciphers
+func Names() []string {
+	mu.RLock()
+	names := make([]string, 0, len(ciphers))
+	for name := range ciphers {
+		names = append(names, name)
+	}
+	mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Encrypt encrypt plainText by the cipher registered with name
+func Encrypt(name string, plainText, key []byte, opts ...Option) ([]byte, error) {
+	c, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Encrypt(plainText, key, opts...)
+}
+
+// Decrypt decrypt cipherText by the cipher registered with name
+func Decrypt(name string, cipherText, key []byte, opts ...Option) ([]byte, error) {
+	c, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Decrypt(cipherText, key, opts...)
+}
+
+type aesGCM struct{}
+
+func (aesGCM) Name() string {
+	return AESGCM
+}
+
+func (aesGCM) AEAD() bool {
+	return true
+}
+
+func (aesGCM) Encrypt(plainText, key []byte, opts ...Option) ([]byte, error) {
+	option := newOptions(opts...)
+	return aes.GCMEncrypt(plainText, key, option.AdditionalData)
+}
+
+func (aesGCM) Decrypt(cipherText, key []byte, opts ...Option) ([]byte, error) {
+	option := newOptions(opts...)
+	return aes.GCMDecrypt(cipherText, key, option.AdditionalData)
+}
+
+type aesCBC struct{}
+
+func (aesCBC) Name() string {
+	return AESCBC
+}
+
+func (aesCBC) Encrypt(plainText, key []byte, opts ...Option) ([]byte, error) {
+	option := newOptions(opts...)
+	if len(option.AdditionalData) > 0 {
+		return nil, ErrAdditionalDataNotSupported
+	}
+	return aes.CBCEncryptRandomIV(plainText, key)
+}
+
+func (aesCBC) Decrypt(cipherText, key []byte, opts ...Option) ([]byte, error) {
+	option := newOptions(opts...)
+	if len(option.AdditionalData) > 0 {
+		return nil, ErrAdditionalDataNotSupported
+	}
+	return aes.CBCDecryptRandomIV(cipherText, key)
+}
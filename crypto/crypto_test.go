@@ -0,0 +1,52 @@
+package crypto
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCipher(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	plainText := []byte("crypto cipher")
+	for _, name := range Names() {
+		cipherText, err := Encrypt(name, plainText, key)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		pt, err := Decrypt(name, cipherText, key)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if !bytes.Equal(pt, plainText) {
+			t.Errorf("%s: expected:%v,got:%v", name, plainText, pt)
+		}
+	}
+
+	cipherText, err := Encrypt(AESGCM, plainText, key, AdditionalData([]byte("id")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(AESGCM, cipherText, key); err == nil {
+		t.Error("expected error without additional data")
+	}
+	if _, err := Encrypt(AESCBC, plainText, key, AdditionalData([]byte("id"))); err != ErrAdditionalDataNotSupported {
+		t.Errorf("expected:%v,got:%v", ErrAdditionalDataNotSupported, err)
+	}
+	if _, err := Get("chacha20-poly1305"); err != ErrUnknownCipher {
+		t.Errorf("expected:%v,got:%v", ErrUnknownCipher, err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	expected := []string{AESCBC, AESGCM}
+	if names := Names(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected:%v,got:%v", expected, names)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate register")
+		}
+	}()
+	Register(aesGCM{})
+}
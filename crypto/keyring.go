@@ -0,0 +1,232 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/haormj/util/crypto/aes"
+)
+
+// keyring cipher text format
+//
+//	magic(2) | version(1) | key id(4) | cipher text of key's cipher
+//
+// the header is authenticated as additional data by AEAD ciphers, cipher
+// text tagged with a key id in keyring is never decrypted by the legacy
+// key, legacy cipher text may start with the header by chance
+
+const keyringVersion = 1
+
+var keyringMagic = []byte("kr")
+
+const keyringHeaderSize = 2 + 1 + 4
+
+var (
+	// ErrNoActiveKey is returned when encrypting with an empty keyring
+	ErrNoActiveKey = errors.New("crypto: keyring has no active key")
+	// ErrKeyNotFound is returned when key id is not in keyring
+	ErrKeyNotFound = errors.New("crypto: key not found")
+	// ErrDuplicateKey is returned when adding an existing key id
+	ErrDuplicateKey = errors.New("crypto: duplicate key id")
+	// ErrActiveKey is returned when removing the active key
+	ErrActiveKey = errors.New("crypto: can not remove active key")
+	// ErrNotKeyring is returned when cipher text has no key id and there
+	// is no legacy key
+	ErrNotKeyring = errors.New("crypto: cipher text has no key id")
+)
+
+type keyringKey struct {
+	key    []byte
+	cipher Cipher
+}
+
+// Keyring hold versioned keys, it encrypt with the active key and tag
+// cipher text with key id, so keys can be rotated while old cipher text
+// is still decryptable
+type Keyring struct {
+	opts   KeyringOptions
+	mu     sync.RWMutex
+	keys   map[uint32]keyringKey
+	active uint32
+	latest uint32
+}
+
+func NewKeyring(opts ...KeyringOption) *Keyring {
+	return &Keyring{
+		opts: newKeyringOptions(opts...),
+		keys: make(map[uint32]keyringKey),
+	}
+}
+
+// AddKey add key with id, cipher is the registered cipher name, empty
+// means KeyringOptions.Cipher. the first added key becomes active
+func (k *Keyring) AddKey(id uint32, key []byte, cipher string) error {
+	if cipher == "" {
+		cipher = k.opts.Cipher
+	}
+	c, err := Get(cipher)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; ok {
+		return ErrDuplicateKey
+	}
+	k.keys[id] = keyringKey{key: key, cipher: c}
+	if len(k.keys) == 1 {
+		k.active = id
+	}
+	if id > k.latest {
+		k.latest = id
+	}
+	return nil
+}
+
+// Rotate add key with the next id and make it active
+func (k *Keyring) Rotate(key []byte) (uint32, error) {
+	c, err := Get(k.opts.Cipher)
+	if err != nil {
+		return 0, err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	id := k.latest + 1
+	if _, ok := k.keys[id]; ok {
+		return 0, ErrDuplicateKey
+	}
+	k.keys[id] = keyringKey{key: key, cipher: c}
+	k.active, k.latest = id, id
+	return id, nil
+}
+
+// SetActive make key with id used by Encrypt
+func (k *Keyring) SetActive(id uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; !ok {
+		return ErrKeyNotFound
+	}
+	k.active = id
+	return nil
+}
+
+// Active returns id of the active key, false if keyring is empty
+func (k *Keyring) Active() (uint32, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active, len(k.keys) > 0
+}
+
+// RemoveKey remove key with id, cipher text of it can not be decrypted
+// anymore, so re-encrypt them first
+func (k *Keyring) RemoveKey(id uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; !ok {
+		return ErrKeyNotFound
+	}
+	if id == k.active {
+		return ErrActiveKey
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+// Encrypt encrypt plainText with the active key
+func (k *Keyring) Encrypt(plainText []byte, opts ...Option) ([]byte, error) {
+	k.mu.RLock()
+	id := k.active
+	kk, ok := k.keys[id]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, ErrNoActiveKey
+	}
+	header := make([]byte, keyringHeaderSize)
+	copy(header, keyringMagic)
+	header[2] = keyringVersion
+	binary.BigEndian.PutUint32(header[3:], id)
+	cipherText, err := kk.cipher.Encrypt(plainText, kk.key, withHeader(kk.cipher, header, opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, cipherText...), nil
+}
+
+// Decrypt decrypt cipherText with the key it is tagged with, cipher text
+// without key id is decrypted with legacy key
+func (k *Keyring) Decrypt(cipherText []byte, opts ...Option) ([]byte, error) {
+	plainText, _, _, err := k.decrypt(cipherText, opts...)
+	return plainText, err
+}
+
+// KeyID returns the key id cipherText is tagged with
+func (k *Keyring) KeyID(cipherText []byte) (uint32, bool) {
+	if len(cipherText) < keyringHeaderSize ||
+		!bytes.Equal(cipherText[:2], keyringMagic) || cipherText[2] != keyringVersion {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(cipherText[3:keyringHeaderSize]), true
+}
+
+// Reencrypt decrypt cipherText and encrypt it with the active key, it
+// returns cipherText as is and false if it is already encrypted by the
+// active key
+func (k *Keyring) Reencrypt(cipherText []byte, opts ...Option) ([]byte, bool, error) {
+	plainText, id, tagged, err := k.decrypt(cipherText, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+	if active, _ := k.Active(); tagged && id == active {
+		return cipherText, false, nil
+	}
+	cipherText, err = k.Encrypt(plainText, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+	return cipherText, true, nil
+}
+
+// decrypt returns plain text and the key id used, false means legacy key
+func (k *Keyring) decrypt(cipherText []byte, opts ...Option) ([]byte, uint32, bool, error) {
+	id, ok := k.KeyID(cipherText)
+	if !ok {
+		if k.opts.LegacyKey == nil {
+			return nil, 0, false, ErrNotKeyring
+		}
+		plainText, err := aes.CBCDecrypt(cipherText, k.opts.LegacyKey)
+		return plainText, 0, false, err
+	}
+
+	k.mu.RLock()
+	kk, found := k.keys[id]
+	k.mu.RUnlock()
+	if !found {
+		if k.opts.LegacyKey != nil {
+			if plainText, err := aes.CBCDecrypt(cipherText, k.opts.LegacyKey); err == nil {
+				return plainText, 0, false, nil
+			}
+		}
+		return nil, 0, false, ErrKeyNotFound
+	}
+	header := cipherText[:keyringHeaderSize]
+	plainText, err := kk.cipher.Decrypt(cipherText[keyringHeaderSize:], kk.key, withHeader(kk.cipher, header, opts)...)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return plainText, id, true, nil
+}
+
+// withHeader prepend header to additional data of AEAD ciphers
+func withHeader(c Cipher, header []byte, opts []Option) []Option {
+	if a, ok := c.(AEAD); !ok || !a.AEAD() {
+		return opts
+	}
+	option := newOptions(opts...)
+	additionalData := make([]byte, 0, len(header)+len(option.AdditionalData))
+	additionalData = append(additionalData, header...)
+	additionalData = append(additionalData, option.AdditionalData...)
+	return append(opts[:len(opts):len(opts)], AdditionalData(additionalData))
+}
@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"bytes"
+	stdaes "crypto/aes"
+	"testing"
+
+	"github.com/haormj/util/crypto/aes"
+)
+
+func TestKeyring(t *testing.T) {
+	legacyKey := []byte("0123456789abcdef")
+	plainText := []byte("keyring")
+	legacy, err := aes.CBCEncrypt(plainText, legacyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k := NewKeyring(LegacyKey(legacyKey))
+	if _, err := k.Encrypt(plainText); err != ErrNoActiveKey {
+		t.Errorf("expected:%v,got:%v", ErrNoActiveKey, err)
+	}
+	id1, err := k.Rotate([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1, err := k.Encrypt(plainText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := k.KeyID(c1); !ok || id != id1 {
+		t.Errorf("expected:%v,got:%v", id1, id)
+	}
+	id2, err := k.Rotate([]byte("fedcba9876543210fedcba9876543210"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id2 != id1+1 {
+		t.Errorf("expected:%v,got:%v", id1+1, id2)
+	}
+
+	for _, c := range [][]byte{legacy, c1} {
+		pt, err := k.Decrypt(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pt, plainText) {
+			t.Errorf("expected:%v,got:%v", plainText, pt)
+		}
+		c2, changed, err := k.Reencrypt(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !changed {
+			t.Error("expected re-encrypted")
+		}
+		if id, ok := k.KeyID(c2); !ok || id != id2 {
+			t.Errorf("expected:%v,got:%v", id2, id)
+		}
+		c3, changed, err := k.Reencrypt(c2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed || !bytes.Equal(c2, c3) {
+			t.Error("expected cipher text of active key unchanged")
+		}
+	}
+
+	if err := k.RemoveKey(id2); err != ErrActiveKey {
+		t.Errorf("expected:%v,got:%v", ErrActiveKey, err)
+	}
+	if err := k.RemoveKey(id1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Decrypt(c1); err != ErrKeyNotFound {
+		t.Errorf("expected:%v,got:%v", ErrKeyNotFound, err)
+	}
+	if _, err := NewKeyring().Decrypt(legacy); err != ErrNotKeyring {
+		t.Errorf("expected:%v,got:%v", ErrNotKeyring, err)
+	}
+}
+
+func TestKeyringAddKey(t *testing.T) {
+	k := NewKeyring()
+	if err := k.AddKey(7, []byte("0123456789abcdef"), AESCBC); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.AddKey(7, []byte("0123456789abcdef"), ""); err != ErrDuplicateKey {
+		t.Errorf("expected:%v,got:%v", ErrDuplicateKey, err)
+	}
+	if err := k.AddKey(8, []byte("0123456789abcdef"), "unknown"); err != ErrUnknownCipher {
+		t.Errorf("expected:%v,got:%v", ErrUnknownCipher, err)
+	}
+	if id, ok := k.Active(); !ok || id != 7 {
+		t.Errorf("expected:%v,got:%v", 7, id)
+	}
+	if err := k.SetActive(9); err != ErrKeyNotFound {
+		t.Errorf("expected:%v,got:%v", ErrKeyNotFound, err)
+	}
+	cipherText, err := k.Encrypt([]byte("keyring"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := k.Rotate([]byte("fedcba9876543210")); err != nil || id != 8 {
+		t.Fatalf("expected:%v,got:%v,%v", 8, id, err)
+	}
+	if pt, err := k.Decrypt(cipherText); err != nil || string(pt) != "keyring" {
+		t.Errorf("expected:%v,got:%v,%v", "keyring", string(pt), err)
+	}
+}
+
+func TestKeyringTamper(t *testing.T) {
+	legacyKey := []byte("0123456789abcdef")
+	k := NewKeyring(LegacyKey(legacyKey))
+	id, err := k.Rotate([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		cipherText, err := k.Encrypt([]byte("keyring plain"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cipherText[len(cipherText)-1] ^= 1
+		if _, err := k.Decrypt(cipherText); err != aes.ErrAuthentication {
+			t.Fatalf("expected:%v,got:%v", aes.ErrAuthentication, err)
+		}
+		if _, _, err := k.Reencrypt(cipherText); err != aes.ErrAuthentication {
+			t.Fatalf("expected:%v,got:%v", aes.ErrAuthentication, err)
+		}
+	}
+
+	// header is authenticated, moving cipher text to another key id of
+	// the same key is detected
+	if err := k.AddKey(id+1, []byte("0123456789abcdef0123456789abcdef"), ""); err != nil {
+		t.Fatal(err)
+	}
+	cipherText, err := k.Encrypt([]byte("keyring plain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipherText[6]++
+	if _, err := k.Decrypt(cipherText); err != aes.ErrAuthentication {
+		t.Errorf("expected:%v,got:%v", aes.ErrAuthentication, err)
+	}
+	cipherText[6] += 10
+	if _, err := NewKeyring().Decrypt(cipherText); err != ErrKeyNotFound {
+		t.Errorf("expected:%v,got:%v", ErrKeyNotFound, err)
+	}
+}
+
+func TestKeyringLegacyHeader(t *testing.T) {
+	legacyKey := []byte("0123456789abcdef")
+	// choose plain text whose legacy cipher text start with the header
+	// of an unknown key id
+	block, err := stdaes.NewCipher(legacyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainText := make([]byte, stdaes.BlockSize)
+	block.Decrypt(plainText, []byte("kr\x01\xff\xff\xff\xffabcdefghi"))
+	for i := range plainText {
+		plainText[i] ^= legacyKey[i]
+	}
+	legacy, err := aes.CBCEncrypt(plainText, legacyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k := NewKeyring(LegacyKey(legacyKey))
+	if _, err := k.Rotate([]byte("0123456789abcdef0123456789abcdef")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := k.KeyID(legacy); !ok {
+		t.Fatal("expected legacy cipher text start with header")
+	}
+	if pt, err := k.Decrypt(legacy); err != nil || !bytes.Equal(pt, plainText) {
+		t.Errorf("expected:%v,got:%v,%v", plainText, pt, err)
+	}
+}
@@ -0,0 +1,60 @@
+package crypto
+
+type Options struct {
+	// AdditionalData is authenticated but not encrypted, it must be the
+	// same on Encrypt and Decrypt
+	AdditionalData []byte
+}
+
+type Option func(*Options)
+
+func newOptions(opts ...Option) Options {
+	option := Options{}
+
+	for _, o := range opts {
+		o(&option)
+	}
+
+	return option
+}
+
+func AdditionalData(additionalData []byte) Option {
+	return func(o *Options) {
+		o.AdditionalData = additionalData
+	}
+}
+
+type KeyringOptions struct {
+	// Cipher is the name of registered cipher used by keys added without
+	// cipher name
+	Cipher string
+	// LegacyKey decrypt cipher text produced by aes.CBCEncrypt without
+	// key id, such cipher text is re-encrypted to the active key
+	LegacyKey []byte
+}
+
+type KeyringOption func(*KeyringOptions)
+
+func newKeyringOptions(opts ...KeyringOption) KeyringOptions {
+	option := KeyringOptions{
+		Cipher: AESGCM,
+	}
+
+	for _, o := range opts {
+		o(&option)
+	}
+
+	return option
+}
+
+func KeyringCipher(name string) KeyringOption {
+	return func(o *KeyringOptions) {
+		o.Cipher = name
+	}
+}
+
+func LegacyKey(key []byte) KeyringOption {
+	return func(o *KeyringOptions) {
+		o.LegacyKey = key
+	}
+}